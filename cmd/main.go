@@ -1,9 +1,13 @@
 package main
 
 import (
+	"context"
 	"log"
+	"os"
+	"os/signal"
 	_ "songs/docs"
 	"songs/internal/app"
+	"syscall"
 )
 
 // @title Songs API
@@ -14,14 +18,48 @@ import (
 // @license.name MIT
 // @license.url https://opensource.org/licenses/MIT
 // @host localhost:8080
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description JWT в формате "Bearer <токен>". Изменение данных требует роли editor, безвозвратное удаление — admin
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-API-Key
+// @description Ключ API машинного клиента. Области доступа read, write и admin соответствуют ролям reader, editor и admin
+//
+// Использование: songs [serve] — запуск сервера (по умолчанию), songs migrate <команда> — управление миграциями
 func main() {
+	command := "serve"
+	if len(os.Args) > 1 {
+		command = os.Args[1]
+	}
+
+	switch command {
+	case "serve":
+		serve()
+	case "migrate":
+		if err := app.Migrate(os.Args[2:], os.Stdout); err != nil {
+			log.Fatalf("Ошибка управления миграциями: %v", err)
+		}
+	default:
+		log.Fatalf("Неизвестная команда %q. Команды: serve, migrate\n%s", command, app.MigrateUsage)
+	}
+}
+
+// serve запускает сервер и работает до сигнала остановки
+func serve() {
 	a, err := app.New()
 	if err != nil {
 		log.Fatalf("Ошибка инициализации приложения: %v", err)
 	}
 
-	err = a.Run()
+	// SIGINT и SIGTERM запускают плавную остановку: сервер дообрабатывает текущие запросы,
+	// затем останавливаются воркеры и закрывается подключение к базе
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	err = a.Run(ctx)
 	if err != nil {
-		log.Fatalf("Ошибка при запуске приложения: %v", err)
+		log.Fatalf("Ошибка при работе приложения: %v", err)
 	}
 }
@@ -0,0 +1,43 @@
+// Команда token выпускает JWT для локальной разработки и проверки API.
+// Токен подписывается секретом JWT_SECRET из config.env или переменных окружения:
+//
+//	go run ./cmd/token -sub alice -role editor
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"songs/internal/auth"
+	"songs/internal/config"
+)
+
+func main() {
+	subject := flag.String("sub", "", "Пользователь, для которого выпускается токен")
+	roleName := flag.String("role", string(auth.RoleReader), "Роль пользователя: reader, editor, admin")
+	ttl := flag.Duration("ttl", 0, "Срок действия токена, по умолчанию AUTH_TOKEN_TTL")
+	flag.Parse()
+
+	cfg, err := config.New()
+	if err != nil {
+		log.Fatalf("Ошибка загрузки конфигурации: %v", err)
+	}
+
+	role, err := auth.ParseRole(*roleName)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *ttl == 0 {
+		*ttl = cfg.Auth.TokenTTL
+	}
+
+	tokens, err := auth.NewTokenManager(cfg.Server.JWTSecret, cfg.Auth.Issuer, *ttl)
+	if err != nil {
+		log.Fatal(err)
+	}
+	token, err := tokens.Issue(*subject, role)
+	if err != nil {
+		log.Fatalf("Ошибка выпуска токена: %v", err)
+	}
+	fmt.Println(token)
+}
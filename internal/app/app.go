@@ -1,97 +1,283 @@
 package app
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"fmt"
-	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
+	"net/http"
+	"songs/internal/auth"
 	"songs/internal/config"
+	"songs/internal/enrichment"
 	"songs/internal/hanlers"
+	"songs/internal/health"
+	"songs/internal/metrics"
 	"songs/internal/routes"
+	"songs/internal/songinfo"
+	"songs/internal/storages"
 	"songs/internal/storages/postgres"
+	"songs/internal/tracing"
 	"songs/pkg/logger"
+	"songs/pkg/ratelimit"
+	"songs/pkg/resilience"
+	"time"
 )
 
 type App struct {
-	logger *logrus.Logger // Логгер для логирования событий
-	router *gin.Engine    // Gin маршрутизатор для обработки HTTP-запросов // Клиент для работы с Redis
+	logger   *logrus.Logger   // Логгер для логирования событий
+	server   *http.Server     // HTTP-сервер с маршрутизатором Gin
+	enricher *enrichment.Pool // Воркеры фонового обогащения песен
+	health   *health.Checker  // Проверки живости и готовности экземпляра
+	// Пауза между переводом /readyz в отказ и остановкой сервера
+	drainDelay time.Duration
+	// Время на завершение обрабатываемых запросов при остановке
+	shutdownTimeout time.Duration
+	// Дописывает накопленные спаны и останавливает экспортёр трасс
+	shutdownTracing func(context.Context) error
 }
 
-// New создаёт новый экземпляр приложения, инициализирует все необходимые сервисы
-func New() (*App, error) {
-	// Инициализация логгера с помощью вспомогательной функции
-	log := logger.InitLogger()
-
+// New создаёт новый экземпляр приложения, инициализирует все необходимые сервисы.
+// Ошибки инициализации возвращаются вызывающему коду, уже открытое подключение к базе при этом закрывается
+func New() (a *App, err error) {
 	// Загрузка конфигурации из переменных окружения или файла
 	cfg, err := config.New()
 	if err != nil {
-		// Логирование ошибки и завершение работы, если конфигурация не загружена
-		log.Fatalf("Ошибка загрузки конфигурации: %v", err)
-		return nil, err
+		return nil, fmt.Errorf("ошибка загрузки конфигурации: %w", err)
 	}
 
-	// Инициализация подключения к базе данных PostgreSQL с параметрами из конфигурации
-	db, err := postgres.NewPostgresConnection(postgres.ConnectionInfo{
-		Host:     cfg.DB.Host,
-		Port:     cfg.DB.Port,
-		Username: cfg.DB.Username,
-		DBName:   cfg.DB.Name,
-		SSLMode:  cfg.DB.SSLMode,
-		Password: cfg.DB.Password,
+	// Инициализация логгера с уровнем, форматом и выводом из конфигурации
+	log, err := logger.InitLogger(logger.Options{
+		Level:      cfg.Log.Level,
+		Format:     cfg.Log.Format,
+		Output:     cfg.Log.Output,
+		File:       cfg.Log.File,
+		MaxSizeMB:  cfg.Log.MaxSizeMB,
+		MaxBackups: cfg.Log.MaxBackups,
+		MaxAgeDays: cfg.Log.MaxAgeDays,
+		Compress:   cfg.Log.Compress,
 	})
 	if err != nil {
-		// Логирование ошибки подключения и завершение работы, если соединение не удалось
-		log.Fatal(err)
+		return nil, fmt.Errorf("ошибка настройки журнала: %w", err)
+	}
+
+	// Трассировка настраивается до подключения к базе, чтобы запросы к ней попадали в трассы
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Options{
+		Exporter:    cfg.Tracing.Exporter,
+		File:        cfg.Tracing.File,
+		Endpoint:    cfg.Tracing.Endpoint,
+		Insecure:    cfg.Tracing.Insecure,
+		SampleRatio: cfg.Tracing.SampleRatio,
+		ServiceName: cfg.Tracing.ServiceName,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка настройки трассировки: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			shutdownTracing(context.Background())
+		}
+	}()
+
+	// Инициализация подключения к базе данных PostgreSQL с параметрами из конфигурации
+	db, err := connectDB(cfg)
+	if err != nil {
 		return nil, err
 	}
 
-	// Устанавливаем подключение к базе данных и запускаем миграции
+	// Устанавливаем подключение к базе данных и, если это не отключено, применяем миграции
 	postgres.SetDB(db)
-	postgres.RunMigrations()
+	defer func() {
+		if err != nil {
+			postgres.CloseDB()
+		}
+	}()
+	if cfg.DB.AutoMigrate {
+		if err := postgres.RunMigrations(); err != nil {
+			return nil, fmt.Errorf("ошибка при применении миграций: %w", err)
+		}
+	} else {
+		log.Info("Автоматическое применение миграций отключено")
+	}
+
+	// Создание хранилища данных для работы с PostgreSQL с ограничениями времени запросов из конфигурации
+	storage := postgres.NewPostgresStorage(db, log, postgres.Timeouts{
+		Read:   cfg.DB.ReadTimeout,
+		Write:  cfg.DB.WriteTimeout,
+		Search: cfg.DB.SearchTimeout,
+	})
+
+	// Источники информации о песнях в порядке, заданном в конфигурации
+	songInfo, err := songinfo.NewFromConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при настройке провайдеров информации о песнях: %w", err)
+	}
+
+	// Проверки готовности: база доступна и её схема соответствует миграциям этой сборки.
+	// Внешний API проверяется по настройке и не влияет на готовность
+	latest, err := postgres.LatestMigration()
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при чтении миграций: %w", err)
+	}
+	checks := []health.Check{
+		{Name: "postgres", Critical: true, Probe: db.PingContext},
+		{Name: "migrations", Critical: true, Probe: func(ctx context.Context) error {
+			return postgres.CheckMigrations(ctx, db, latest)
+		}},
+	}
+	if cfg.Health.ExternalAPI {
+		for i, provider := range songinfo.HTTPProviders(songInfo) {
+			name := "external_api"
+			if i > 0 {
+				name = fmt.Sprintf("external_api_%d", i+1)
+			}
+			checks = append(checks, health.Check{Name: name, Probe: provider.Ping})
+		}
+	}
+	checker := health.New(cfg.Health.Timeout, log, checks...)
+
+	// Каждый вызов хранилища открывает спан, запросы к базе становятся его дочерними спанами
+	var backend storages.Backend = tracing.NewStorage(storage)
 
-	// Создание хранилища данных для работы с PostgreSQL
-	storage := postgres.NewPostgresStorage(db)
+	// Метрики Prometheus. Хранилище и источники информации о песнях оборачиваются декораторами,
+	// показатели пула соединений и клиента внешнего API снимаются при каждом опросе
+	var m *metrics.Metrics
+	if cfg.Metrics.Enabled {
+		m = metrics.New()
+		if err := m.RegisterDB(db, cfg.DB.Name); err != nil {
+			return nil, fmt.Errorf("ошибка при регистрации метрик базы данных: %w", err)
+		}
+		for _, provider := range songinfo.HTTPProviders(songInfo) {
+			if err := m.RegisterExternalAPI(provider); err != nil {
+				return nil, fmt.Errorf("ошибка при регистрации метрик внешнего API: %w", err)
+			}
+		}
+		backend = m.Storage(backend)
+		songInfo = m.SongInfo(songInfo)
+	}
+
+	// Воркеры, заполняющие данные песен из внешних источников
+	enricher := enrichment.NewPool(backend, songInfo, log, enrichment.Options{
+		Workers:      cfg.Enrichment.Workers,
+		PollInterval: cfg.Enrichment.PollInterval,
+		Lease:        cfg.Enrichment.Lease,
+		MaxAttempts:  cfg.Enrichment.MaxAttempts,
+		Backoff: resilience.Backoff{
+			Initial: cfg.Enrichment.RetryInitial,
+			Max:     cfg.Enrichment.RetryMax,
+		},
+	})
 
-	err = storage.CreateIndexes()
+	// Проверка JWT, которыми пользователи подтверждают свою роль
+	tokens, err := auth.NewTokenManager(cfg.Server.JWTSecret, cfg.Auth.Issuer, cfg.Auth.TokenTTL)
 	if err != nil {
-		return nil, fmt.Errorf("ошибка при создании индексов: %v", err)
+		return nil, fmt.Errorf("ошибка при настройке аутентификации: %w", err)
 	}
 
 	// Создание обработчиков для аутентификации и обмена валютами
-	Handler := hanlers.NewHandler(storage, log, cfg)
+	Handler := hanlers.NewHandler(backend, backend, log, cfg)
 
-	// Настройка маршрутов для HTTP-сервера с использованием Gin
-	router := routes.SetupRouter(Handler)
+	// Настройка маршрутов для HTTP-сервера с использованием Gin.
+	// Клиенты аутентифицируются ключом API или JWT, лимиты запросов считаются в памяти процесса
+	router, err := routes.SetupRouter(Handler, auth.Authenticate(tokens, backend, log), ratelimit.NewMemoryStore(), checker, m, cfg, log)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при настройке маршрутов: %w", err)
+	}
 
-	// Возвращаем структуру приложения с логгером и маршрутизатором
+	// Возвращаем структуру приложения с логгером, сервером и воркерами
 	return &App{
 		logger: log,
-		router: router,
+		server: &http.Server{
+			Addr:         fmt.Sprintf(":%d", cfg.Server.Port),
+			Handler:      router,
+			ReadTimeout:  cfg.Server.ReadTimeout,
+			WriteTimeout: cfg.Server.WriteTimeout,
+			IdleTimeout:  cfg.Server.IdleTimeout,
+		},
+		enricher:        enricher,
+		health:          checker,
+		drainDelay:      cfg.Health.DrainDelay,
+		shutdownTimeout: cfg.Server.ShutdownTimeout,
+		shutdownTracing: shutdownTracing,
 	}, nil
 }
 
-// Run запускает сервер приложения
-// Он запускает HTTP-сервер на порту, указанном в конфигурации
-func (a *App) Run() error {
-	// Загрузка конфигурации для получения порта сервера
-	cfg, err := config.New()
-	if err != nil {
-		// Логирование ошибки, если не удалось загрузить конфигурацию
-		a.logger.Fatalf("Ошибка загрузки конфигурации: %v", err)
-		return err
+// Run запускает фоновые воркеры и HTTP-сервер и работает до отмены ctx или ошибки сервера.
+// После этого приложение останавливается через shutdown
+func (a *App) Run(ctx context.Context) error {
+	// Воркеры обогащения останавливаются явно после HTTP-сервера,
+	// поэтому не зависят от ctx и успевают взять задачи, поставленные последними запросами
+	a.enricher.Start(context.Background())
+
+	serverErr := make(chan error, 1)
+	go func() {
+		a.logger.Printf("Запуск сервера на адресе %s", a.server.Addr)
+		if err := a.server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- err
+		}
+	}()
+
+	var runErr error
+	select {
+	case <-ctx.Done():
+		a.logger.Info("Получен сигнал остановки, завершение работы")
+	case err := <-serverErr:
+		runErr = fmt.Errorf("ошибка запуска сервера: %w", err)
 	}
 
-	// Порт, на котором будет работать сервер
-	port := cfg.Server.Port
-	a.logger.Printf("Запуск сервера на порту: %d", port)
+	return errors.Join(runErr, a.shutdown())
+}
 
-	// Запускаем сервер с использованием маршрутизатора и указываем порт для прослушивания
-	err = a.router.Run(fmt.Sprintf(":%d", port))
-	if err != nil {
-		// Логирование ошибки, если сервер не смог запуститься
-		a.logger.Fatalf("Ошибка запуска сервера: %v", err)
-		return err
+// shutdown останавливает приложение по шагам: /readyz начинает отвечать отказом, сервер перестаёт
+// принимать соединения и дожидается обрабатываемых запросов, затем останавливаются воркеры обогащения,
+// закрывается пул соединений с базой и экспортируются оставшиеся спаны
+func (a *App) shutdown() error {
+	// Балансировщик получает время заметить неготовность, пока сервер ещё принимает запросы
+	a.health.Drain()
+	if a.drainDelay > 0 {
+		a.logger.Infof("Экземпляр помечен неготовым, остановка сервера через %s", a.drainDelay)
+		time.Sleep(a.drainDelay)
+	}
+
+	// Время на остановку отсчитывается после паузы, чтобы она не сокращала его
+	ctx, cancel := context.WithTimeout(context.Background(), a.shutdownTimeout)
+	defer cancel()
+
+	var errs []error
+	if err := a.server.Shutdown(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("ошибка остановки сервера: %w", err))
+	} else {
+		a.logger.Info("HTTP-сервер остановлен")
+	}
+
+	a.enricher.Stop()
+	a.logger.Info("Воркеры обогащения остановлены")
+
+	if err := postgres.CloseDB(); err != nil {
+		errs = append(errs, fmt.Errorf("ошибка закрытия подключения к базе данных: %w", err))
+	} else {
+		a.logger.Info("Подключение к базе данных закрыто")
+	}
+
+	// Спаны последних запросов и задач экспортируются до выхода из процесса
+	if err := a.shutdownTracing(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("ошибка остановки трассировки: %w", err))
 	}
+	return errors.Join(errs...)
+}
 
-	// Если сервер успешно запустился, возвращаем nil
-	return nil
+// connectDB открывает пул соединений с базой данных PostgreSQL по параметрам из конфигурации
+func connectDB(cfg *config.Config) (*sql.DB, error) {
+	db, err := postgres.NewPostgresConnection(postgres.ConnectionInfo{
+		Host:     cfg.DB.Host,
+		Port:     cfg.DB.Port,
+		Username: cfg.DB.Username,
+		DBName:   cfg.DB.Name,
+		SSLMode:  cfg.DB.SSLMode,
+		Password: cfg.DB.Password,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка подключения к базе данных: %w", err)
+	}
+	return db, nil
 }
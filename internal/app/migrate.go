@@ -0,0 +1,153 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"songs/internal/config"
+	"songs/internal/storages/postgres"
+	"strconv"
+)
+
+// MigrateUsage описание команд управления миграциями
+const MigrateUsage = `migrate up          применить все миграции
+migrate down N      откатить N последних миграций
+migrate goto V      перейти к версии V
+migrate version     показать текущую и последнюю версию схемы
+migrate force V     записать версию V без выполнения миграций (после ручного исправления базы)
+migrate create NAME создать пустую пару файлов миграции в каталоге migration`
+
+// migrationsDir каталог исходных файлов миграций, в котором migrate create создаёт новые файлы.
+// Команда предназначена для разработки и запускается из корня репозитория
+const migrationsDir = "migration"
+
+var migrationName = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+// Migrate выполняет команду управления миграциями args (без слова migrate) и пишет результат в out
+func Migrate(args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return fmt.Errorf("не указана команда\n%s", MigrateUsage)
+	}
+	command, args := args[0], args[1:]
+
+	// Создание файлов не требует подключения к базе
+	if command == "create" {
+		if len(args) != 1 {
+			return fmt.Errorf("использование: migrate create NAME")
+		}
+		return createMigration(args[0], out)
+	}
+
+	// Проверяем аргументы до подключения к базе
+	var n int
+	switch command {
+	case "up", "version":
+		if len(args) != 0 {
+			return fmt.Errorf("команда %s не принимает аргументов", command)
+		}
+	case "down", "goto", "force":
+		if len(args) != 1 {
+			return fmt.Errorf("команде %s нужен один числовой аргумент\n%s", command, MigrateUsage)
+		}
+		var err error
+		if n, err = strconv.Atoi(args[0]); err != nil {
+			return fmt.Errorf("некорректное число %q", args[0])
+		}
+		if command == "goto" && n < 0 {
+			return fmt.Errorf("некорректная версия %d", n)
+		}
+	default:
+		return fmt.Errorf("неизвестная команда %q\n%s", command, MigrateUsage)
+	}
+
+	cfg, err := config.New()
+	if err != nil {
+		return fmt.Errorf("ошибка загрузки конфигурации: %w", err)
+	}
+	db, err := connectDB(cfg)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	m, err := postgres.NewMigrator(db)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	switch command {
+	case "up":
+		err = m.Up()
+	case "down":
+		err = m.Down(n)
+	case "goto":
+		err = m.Goto(uint(n))
+	case "force":
+		err = m.Force(n)
+	}
+	if errors.Is(err, postgres.ErrNoChange) {
+		fmt.Fprintln(out, "Схема уже в запрошенной версии")
+	} else if err != nil {
+		return fmt.Errorf("ошибка выполнения migrate %s: %w", command, err)
+	}
+
+	return printVersion(m, out)
+}
+
+// printVersion выводит текущую версию схемы и последнюю встроенную миграцию
+func printVersion(m *postgres.Migrator, out io.Writer) error {
+	latest, err := postgres.LatestMigration()
+	if err != nil {
+		return err
+	}
+	version, dirty, err := m.Version()
+	switch {
+	case errors.Is(err, postgres.ErrNilVersion):
+		fmt.Fprintf(out, "Миграции не применены, последняя версия: %d\n", latest)
+	case err != nil:
+		return fmt.Errorf("ошибка чтения версии схемы: %w", err)
+	case dirty:
+		fmt.Fprintf(out, "Версия схемы: %d (миграция не завершена, исправьте базу и выполните migrate force), последняя версия: %d\n", version, latest)
+	default:
+		fmt.Fprintf(out, "Версия схемы: %d, последняя версия: %d\n", version, latest)
+	}
+	return nil
+}
+
+// createMigration создаёт пустые файлы up и down со следующим по порядку номером
+func createMigration(name string, out io.Writer) error {
+	if !migrationName.MatchString(name) {
+		return fmt.Errorf("имя миграции может содержать только строчные латинские буквы, цифры и _: %q", name)
+	}
+
+	// Номер берётся из каталога, а не из встроенных файлов: в нём могут быть ещё не собранные миграции
+	files, err := filepath.Glob(filepath.Join(migrationsDir, "*.sql"))
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("в каталоге %s нет миграций, команду нужно запускать из корня репозитория", migrationsDir)
+	}
+	var latest uint64
+	for _, file := range files {
+		var version uint64
+		if _, err := fmt.Sscanf(filepath.Base(file), "%d_", &version); err == nil && version > latest {
+			latest = version
+		}
+	}
+
+	for _, direction := range []string{"up", "down"} {
+		path := filepath.Join(migrationsDir, fmt.Sprintf("%06d_%s.%s.sql", latest+1, name, direction))
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err != nil {
+			return err
+		}
+		f.Close()
+		fmt.Fprintln(out, "Создан файл", path)
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+)
+
+// Области доступа ключей API. Каждая соответствует роли пользователя
+const (
+	ScopeRead  = "read"
+	ScopeWrite = "write"
+	ScopeAdmin = "admin"
+)
+
+var scopeRoles = map[string]Role{
+	ScopeRead:  RoleReader,
+	ScopeWrite: RoleEditor,
+	ScopeAdmin: RoleAdmin,
+}
+
+const (
+	// apiKeyPrefix отличает ключи сервиса от других секретов, например при поиске утечек
+	apiKeyPrefix = "songs_"
+	// apiKeyVisible количество символов ключа, которые хранятся открыто для распознавания
+	apiKeyVisible = len(apiKeyPrefix) + 8
+)
+
+// ParseScopes проверяет области доступа и убирает повторы
+func ParseScopes(scopes []string) ([]string, error) {
+	if len(scopes) == 0 {
+		return nil, fmt.Errorf("нужно указать хотя бы одну область доступа: read, write, admin")
+	}
+	var result []string
+	seen := make(map[string]bool)
+	for _, scope := range scopes {
+		if _, ok := scopeRoles[scope]; !ok {
+			return nil, fmt.Errorf("неизвестная область доступа %q, допустимы: read, write, admin", scope)
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			result = append(result, scope)
+		}
+	}
+	return result, nil
+}
+
+// RoleForScopes возвращает роль, соответствующую самой широкой из областей доступа ключа
+func RoleForScopes(scopes []string) (Role, bool) {
+	var role Role
+	for _, scope := range scopes {
+		if r, ok := scopeRoles[scope]; ok && !role.Allows(r) {
+			role = r
+		}
+	}
+	return role, role != ""
+}
+
+// GenerateAPIKey создаёт новый ключ API. Возвращает сам ключ, который показывается клиенту
+// один раз, его открытое начало и хэш для хранения
+func GenerateAPIKey() (key string, prefix string, hash string, err error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", "", err
+	}
+	key = apiKeyPrefix + base64.RawURLEncoding.EncodeToString(secret)
+	return key, key[:apiKeyVisible], HashAPIKey(key), nil
+}
+
+// HashAPIKey вычисляет хэш ключа для поиска в хранилище. Ключ случайный и длинный,
+// поэтому медленный хэш с солью, как для паролей, не требуется
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
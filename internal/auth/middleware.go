@@ -0,0 +1,121 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"songs/internal/problem"
+	"songs/internal/storages"
+	"strings"
+)
+
+const principalKey = "auth.principal"
+
+// APIKeyHeader заголовок, в котором машинные клиенты передают ключ API
+const APIKeyHeader = "X-API-Key"
+
+// APIKeySubjectPrefix префикс Subject клиентов, вошедших по ключу API. JWT с таким Subject
+// не принимаются, чтобы пользователь токена не выдавал себя за ключ в журнале изменений
+const APIKeySubjectPrefix = "apikey:"
+
+// Principal аутентифицированный клиент запроса. Для ключа API Subject имеет вид apikey:<ID>
+type Principal struct {
+	Subject string
+	Role    Role
+}
+
+// APIKeyVerifier находит действующий ключ API по его хэшу
+type APIKeyVerifier interface {
+	AuthenticateAPIKey(ctx context.Context, hash string) (storages.APIKey, error)
+}
+
+// PrincipalFrom возвращает клиента, аутентифицированного middleware Authenticate
+func PrincipalFrom(c *gin.Context) (Principal, bool) {
+	value, ok := c.Get(principalKey)
+	if !ok {
+		return Principal{}, false
+	}
+	principal, ok := value.(Principal)
+	return principal, ok
+}
+
+// Authenticate определяет клиента по ключу API из заголовка X-API-Key либо по JWT из заголовка
+// Authorization: Bearer и сохраняет его в контексте. Запрос без учётных данных проходит анонимно,
+// права проверяет RequireRole. Недействительные учётные данные отклоняются сразу,
+// чтобы клиент не получил молча анонимный доступ
+func Authenticate(tokens *TokenManager, keys APIKeyVerifier, logger *logrus.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if key := strings.TrimSpace(c.GetHeader(APIKeyHeader)); key != "" {
+			authenticateAPIKey(c, keys, key, logger)
+			return
+		}
+
+		header := c.GetHeader("Authorization")
+		if header == "" {
+			c.Next()
+			return
+		}
+
+		scheme, token, found := strings.Cut(header, " ")
+		if !found || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+			unauthorized(c, "Ожидается заголовок Authorization: Bearer <токен>")
+			return
+		}
+
+		claims, err := tokens.Parse(strings.TrimSpace(token))
+		if err != nil {
+			c.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+			problem.Respond(c, http.StatusUnauthorized, err.Error())
+			return
+		}
+
+		c.Set(principalKey, Principal{Subject: claims.Subject, Role: claims.Role})
+		c.Next()
+	}
+}
+
+func authenticateAPIKey(c *gin.Context, keys APIKeyVerifier, key string, logger *logrus.Logger) {
+	apiKey, err := keys.AuthenticateAPIKey(c.Request.Context(), HashAPIKey(key))
+	if errors.Is(err, storages.ErrNotFound) {
+		unauthorized(c, "Недействительный ключ API")
+		return
+	}
+	if err != nil {
+		logger.WithContext(c.Request.Context()).Errorf("Не удалось проверить ключ API: %v", err)
+		problem.Respond(c, http.StatusInternalServerError, "Не удалось проверить ключ API")
+		return
+	}
+
+	role, ok := RoleForScopes(apiKey.Scopes)
+	if !ok {
+		unauthorized(c, "У ключа API нет областей доступа")
+		return
+	}
+
+	c.Set(principalKey, Principal{Subject: fmt.Sprintf("%s%d", APIKeySubjectPrefix, apiKey.ID), Role: role})
+	c.Next()
+}
+
+// RequireRole пропускает только клиентов с ролью не ниже required
+func RequireRole(required Role) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, ok := PrincipalFrom(c)
+		if !ok {
+			unauthorized(c, "Требуется аутентификация")
+			return
+		}
+		if !principal.Role.Allows(required) {
+			problem.Respond(c, http.StatusForbidden, "Недостаточно прав: требуется роль "+string(required))
+			return
+		}
+		c.Next()
+	}
+}
+
+func unauthorized(c *gin.Context, message string) {
+	c.Header("WWW-Authenticate", "Bearer")
+	problem.Respond(c, http.StatusUnauthorized, message)
+}
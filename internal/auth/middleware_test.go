@@ -0,0 +1,96 @@
+package auth
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newRoleRouter собирает маршрутизатор, в котором клиент запроса берётся из principal,
+// а маршрут требует роль required
+func newRoleRouter(principal *Principal, required Role) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/", func(c *gin.Context) {
+		if principal != nil {
+			c.Set(principalKey, *principal)
+		}
+		c.Next()
+	}, RequireRole(required), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	return router
+}
+
+func TestRequireRole(t *testing.T) {
+	tests := []struct {
+		name      string
+		principal *Principal
+		required  Role
+		want      int
+	}{
+		{name: "анонимный клиент", required: RoleReader, want: http.StatusUnauthorized},
+		{name: "роли недостаточно", principal: &Principal{Subject: "alice", Role: RoleReader}, required: RoleEditor, want: http.StatusForbidden},
+		{name: "редактор не удаляет безвозвратно", principal: &Principal{Subject: "alice", Role: RoleEditor}, required: RoleAdmin, want: http.StatusForbidden},
+		{name: "роль совпадает", principal: &Principal{Subject: "alice", Role: RoleEditor}, required: RoleEditor, want: http.StatusNoContent},
+		{name: "старшая роль включает младшую", principal: &Principal{Subject: "alice", Role: RoleAdmin}, required: RoleReader, want: http.StatusNoContent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			newRoleRouter(tt.principal, tt.required).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+			if w.Code != tt.want {
+				t.Fatalf("код %d, ожидается %d", w.Code, tt.want)
+			}
+			if tt.want == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") == "" {
+				t.Fatal("в ответе 401 нет заголовка WWW-Authenticate")
+			}
+		})
+	}
+}
+
+func TestAuthenticateBearer(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	m := newTestTokenManager(t)
+	editorToken, err := m.Issue("alice", RoleEditor)
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+
+	router := gin.New()
+	router.Use(Authenticate(m, nil, nil))
+	router.GET("/", RequireRole(RoleEditor), func(c *gin.Context) {
+		principal, _ := PrincipalFrom(c)
+		c.String(http.StatusOK, principal.Subject)
+	})
+
+	tests := []struct {
+		name          string
+		authorization string
+		want          int
+	}{
+		{name: "без учётных данных", want: http.StatusUnauthorized},
+		{name: "действительный токен", authorization: "Bearer " + editorToken, want: http.StatusOK},
+		{name: "схема без Bearer", authorization: "Basic " + editorToken, want: http.StatusUnauthorized},
+		{name: "недействительный токен", authorization: "Bearer " + editorToken + "x", want: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			if w.Code != tt.want {
+				t.Fatalf("код %d, ожидается %d: %s", w.Code, tt.want, w.Body)
+			}
+			if tt.want == http.StatusOK && w.Body.String() != "alice" {
+				t.Fatalf("клиент %q, ожидается alice", w.Body)
+			}
+		})
+	}
+}
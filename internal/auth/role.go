@@ -0,0 +1,35 @@
+package auth
+
+import "fmt"
+
+// Role роль пользователя API. Роли упорядочены: каждая следующая включает права предыдущей
+type Role string
+
+const (
+	// RoleReader может только читать данные
+	RoleReader Role = "reader"
+	// RoleEditor может добавлять и изменять песни и группы
+	RoleEditor Role = "editor"
+	// RoleAdmin дополнительно может безвозвратно удалять данные
+	RoleAdmin Role = "admin"
+)
+
+var roleRank = map[Role]int{
+	RoleReader: 1,
+	RoleEditor: 2,
+	RoleAdmin:  3,
+}
+
+// ParseRole проверяет название роли
+func ParseRole(value string) (Role, error) {
+	role := Role(value)
+	if _, ok := roleRank[role]; !ok {
+		return "", fmt.Errorf("неизвестная роль %q, допустимы: reader, editor, admin", value)
+	}
+	return role, nil
+}
+
+// Allows сообщает, достаточно ли роли прав роли required
+func (r Role) Allows(required Role) bool {
+	return roleRank[r] >= roleRank[required]
+}
@@ -0,0 +1,88 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"strings"
+	"time"
+)
+
+// minSecretLength минимальная длина ключа подписи HS256
+const minSecretLength = 32
+
+// ErrInvalidToken возвращается, если токен повреждён, просрочен или подписан другим ключом
+var ErrInvalidToken = errors.New("недействительный токен")
+
+// Claims утверждения JWT сервиса: стандартные поля и роль пользователя
+type Claims struct {
+	Role Role `json:"role"`
+	jwt.RegisteredClaims
+}
+
+// TokenManager выпускает и проверяет JWT, подписанные общим секретом (HS256)
+type TokenManager struct {
+	secret []byte
+	issuer string
+	ttl    time.Duration
+}
+
+// NewTokenManager создаёт менеджер токенов. Короткий секрет отклоняется,
+// так как подпись HS256 с ним легко подобрать
+func NewTokenManager(secret string, issuer string, ttl time.Duration) (*TokenManager, error) {
+	if len(secret) < minSecretLength {
+		return nil, fmt.Errorf("секрет JWT должен быть не короче %d байт", minSecretLength)
+	}
+	return &TokenManager{secret: []byte(secret), issuer: issuer, ttl: ttl}, nil
+}
+
+// Issue выпускает токен для пользователя subject с ролью role
+func (m *TokenManager) Issue(subject string, role Role) (string, error) {
+	if subject == "" {
+		return "", errors.New("не задан пользователь токена")
+	}
+	if strings.HasPrefix(subject, APIKeySubjectPrefix) {
+		return "", fmt.Errorf("пользователь токена не может начинаться с %s", APIKeySubjectPrefix)
+	}
+	if _, err := ParseRole(string(role)); err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	claims := Claims{
+		Role: role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   subject,
+			Issuer:    m.issuer,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(m.ttl)),
+		},
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(m.secret)
+}
+
+// Parse проверяет подпись, срок действия, издателя и роль токена.
+// Принимаются только токены HS256, чтобы нельзя было подменить алгоритм на none
+func (m *TokenManager) Parse(token string) (*Claims, error) {
+	claims := &Claims{}
+	_, err := jwt.ParseWithClaims(token, claims, func(*jwt.Token) (any, error) {
+		return m.secret, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(m.issuer),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("%w: не задан пользователь", ErrInvalidToken)
+	}
+	if strings.HasPrefix(claims.Subject, APIKeySubjectPrefix) {
+		return nil, fmt.Errorf("%w: пользователь из пространства имён ключей API", ErrInvalidToken)
+	}
+	if _, err := ParseRole(string(claims.Role)); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	return claims, nil
+}
@@ -0,0 +1,176 @@
+package auth
+
+import (
+	"errors"
+	"github.com/golang-jwt/jwt/v5"
+	"testing"
+	"time"
+)
+
+const (
+	testSecret = "0123456789abcdef0123456789abcdef"
+	testIssuer = "songs"
+)
+
+func newTestTokenManager(t *testing.T) *TokenManager {
+	t.Helper()
+	m, err := NewTokenManager(testSecret, testIssuer, time.Hour)
+	if err != nil {
+		t.Fatalf("NewTokenManager: %v", err)
+	}
+	return m
+}
+
+// sign подписывает произвольные утверждения, как это сделал бы посторонний выпускающий
+func sign(t *testing.T, method jwt.SigningMethod, key any, claims Claims) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(method, claims).SignedString(key)
+	if err != nil {
+		t.Fatalf("подпись токена: %v", err)
+	}
+	return token
+}
+
+func validClaims() Claims {
+	now := time.Now()
+	return Claims{
+		Role: RoleEditor,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   "alice",
+			Issuer:    testIssuer,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+		},
+	}
+}
+
+func TestNewTokenManagerRejectsShortSecret(t *testing.T) {
+	if _, err := NewTokenManager("short", testIssuer, time.Hour); err == nil {
+		t.Fatal("ожидается ошибка для короткого секрета")
+	}
+}
+
+func TestIssueParseRoundTrip(t *testing.T) {
+	m := newTestTokenManager(t)
+	token, err := m.Issue("alice", RoleAdmin)
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+
+	claims, err := m.Parse(token)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if claims.Subject != "alice" || claims.Role != RoleAdmin || claims.Issuer != testIssuer {
+		t.Fatalf("неожиданные утверждения: %+v", claims)
+	}
+	if ttl := claims.ExpiresAt.Sub(claims.IssuedAt.Time); ttl != time.Hour {
+		t.Fatalf("срок действия %v, ожидается %v", ttl, time.Hour)
+	}
+}
+
+func TestIssueValidation(t *testing.T) {
+	m := newTestTokenManager(t)
+	if _, err := m.Issue("", RoleReader); err == nil {
+		t.Fatal("ожидается ошибка для пустого пользователя")
+	}
+	if _, err := m.Issue("alice", Role("owner")); err == nil {
+		t.Fatal("ожидается ошибка для неизвестной роли")
+	}
+	if _, err := m.Issue("apikey:1", RoleAdmin); err == nil {
+		t.Fatal("ожидается ошибка для пользователя с префиксом ключа API")
+	}
+}
+
+func TestParseRejects(t *testing.T) {
+	m := newTestTokenManager(t)
+
+	tests := []struct {
+		name  string
+		token func(t *testing.T) string
+	}{
+		{
+			name: "просроченный токен",
+			token: func(t *testing.T) string {
+				claims := validClaims()
+				claims.IssuedAt = jwt.NewNumericDate(time.Now().Add(-2 * time.Hour))
+				claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Hour))
+				return sign(t, jwt.SigningMethodHS256, []byte(testSecret), claims)
+			},
+		},
+		{
+			name: "токен без срока действия",
+			token: func(t *testing.T) string {
+				claims := validClaims()
+				claims.ExpiresAt = nil
+				return sign(t, jwt.SigningMethodHS256, []byte(testSecret), claims)
+			},
+		},
+		{
+			name: "другой издатель",
+			token: func(t *testing.T) string {
+				claims := validClaims()
+				claims.Issuer = "someone-else"
+				return sign(t, jwt.SigningMethodHS256, []byte(testSecret), claims)
+			},
+		},
+		{
+			name: "другой секрет",
+			token: func(t *testing.T) string {
+				return sign(t, jwt.SigningMethodHS256, []byte("fedcba9876543210fedcba9876543210"), validClaims())
+			},
+		},
+		{
+			name: "алгоритм none",
+			token: func(t *testing.T) string {
+				return sign(t, jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, validClaims())
+			},
+		},
+		{
+			name: "алгоритм HS512",
+			token: func(t *testing.T) string {
+				return sign(t, jwt.SigningMethodHS512, []byte(testSecret), validClaims())
+			},
+		},
+		{
+			name: "неизвестная роль",
+			token: func(t *testing.T) string {
+				claims := validClaims()
+				claims.Role = "superuser"
+				return sign(t, jwt.SigningMethodHS256, []byte(testSecret), claims)
+			},
+		},
+		{
+			name: "пустой пользователь",
+			token: func(t *testing.T) string {
+				claims := validClaims()
+				claims.Subject = ""
+				return sign(t, jwt.SigningMethodHS256, []byte(testSecret), claims)
+			},
+		},
+		{
+			name: "пользователь из пространства имён ключей API",
+			token: func(t *testing.T) string {
+				claims := validClaims()
+				claims.Subject = "apikey:1"
+				return sign(t, jwt.SigningMethodHS256, []byte(testSecret), claims)
+			},
+		},
+		{
+			name:  "повреждённый токен",
+			token: func(*testing.T) string { return "not.a.token" },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := m.Parse(tt.token(t))
+			if !errors.Is(err, ErrInvalidToken) {
+				t.Fatalf("ошибка %v, ожидается %v", err, ErrInvalidToken)
+			}
+			if claims != nil {
+				t.Fatalf("возвращены утверждения отклонённого токена: %+v", claims)
+			}
+		})
+	}
+}
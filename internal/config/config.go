@@ -1,9 +1,11 @@
 package config
 
 import (
+	"fmt"
 	"github.com/joho/godotenv"
 	"github.com/kelseyhightower/envconfig"
 	"log"
+	"time"
 )
 
 // Config структура для хранения всей конфигурации приложения
@@ -18,6 +20,96 @@ type Config struct {
 		Port int `envconfig:"SERVER_PORT" default:"8080"`
 		// Секретный ключ для JWT (обязателен)
 		JWTSecret string `envconfig:"JWT_SECRET" required:"true"`
+		// Таймауты HTTP-сервера: чтение запроса вместе с телом, запись ответа
+		// и ожидание следующего запроса по keep-alive соединению
+		ReadTimeout  time.Duration `envconfig:"SERVER_READ_TIMEOUT" default:"10s"`
+		WriteTimeout time.Duration `envconfig:"SERVER_WRITE_TIMEOUT" default:"30s"`
+		IdleTimeout  time.Duration `envconfig:"SERVER_IDLE_TIMEOUT" default:"60s"`
+		// Время на завершение обрабатываемых запросов после сигнала остановки
+		ShutdownTimeout time.Duration `envconfig:"SERVER_SHUTDOWN_TIMEOUT" default:"20s"`
+		// Адреса и подсети (CIDR) обратных прокси через запятую. Только им разрешено передавать
+		// адрес клиента в X-Forwarded-For и X-Real-IP. По умолчанию заголовкам не доверяют,
+		// иначе клиент мог бы подменять адрес, по которому считаются лимиты запросов
+		TrustedProxies []string `envconfig:"SERVER_TRUSTED_PROXIES"`
+	}
+
+	// Структура для настройки журнала приложения
+	Log struct {
+		// Уровень: debug, info, warn или error
+		Level string `envconfig:"LOG_LEVEL" default:"info"`
+		// Формат записей: text или json
+		Format string `envconfig:"LOG_FORMAT" default:"text"`
+		// Куда писать журнал: stdout, file или both
+		Output string `envconfig:"LOG_OUTPUT" default:"stdout"`
+		// Путь к файлу журнала и его ротация: размер файла в мегабайтах,
+		// количество и возраст в днях хранимых старых файлов, сжатие старых файлов
+		File       string `envconfig:"LOG_FILE" default:"app.log"`
+		MaxSizeMB  int    `envconfig:"LOG_MAX_SIZE_MB" default:"100"`
+		MaxBackups int    `envconfig:"LOG_MAX_BACKUPS" default:"5"`
+		MaxAgeDays int    `envconfig:"LOG_MAX_AGE_DAYS" default:"30"`
+		Compress   bool   `envconfig:"LOG_COMPRESS" default:"true"`
+	}
+
+	// Структура для настройки проверок живости и готовности
+	Health struct {
+		// Время на проверку одной зависимости в /readyz
+		Timeout time.Duration `envconfig:"HEALTH_CHECK_TIMEOUT" default:"2s"`
+		// Проверять доступность внешнего API. Его сбой не делает экземпляр неготовым,
+		// так как песни обогащаются в фоне, а отмечает отчёт как degraded
+		ExternalAPI bool `envconfig:"HEALTH_CHECK_EXTERNAL_API" default:"false"`
+		// Пауза между переводом /readyz в отказ и остановкой сервера при завершении работы,
+		// чтобы балансировщик успел перестать направлять запросы экземпляру
+		DrainDelay time.Duration `envconfig:"HEALTH_DRAIN_DELAY" default:"5s"`
+	}
+
+	// Структура для настройки метрик Prometheus
+	Metrics struct {
+		// Собирать метрики и отдавать их по пути Path
+		Enabled bool   `envconfig:"METRICS_ENABLED" default:"true"`
+		Path    string `envconfig:"METRICS_PATH" default:"/metrics"`
+	}
+
+	// Структура для настройки трассировки OpenTelemetry
+	Tracing struct {
+		// Экспортёр спанов: none, stdout, file или otlp
+		Exporter string `envconfig:"TRACING_EXPORTER" default:"none"`
+		// Файл для экспортёра file, спаны дописываются в JSON по одному на строку
+		File string `envconfig:"TRACING_FILE" default:"traces.json"`
+		// Адрес коллектора OTLP/HTTP для экспортёра otlp и отключение TLS
+		Endpoint string `envconfig:"TRACING_OTLP_ENDPOINT" default:"localhost:4318"`
+		Insecure bool   `envconfig:"TRACING_OTLP_INSECURE" default:"true"`
+		// Доля записываемых трасс от 0 до 1
+		SampleRatio float64 `envconfig:"TRACING_SAMPLE_RATIO" default:"1"`
+		// Имя сервиса в трассах
+		ServiceName string `envconfig:"TRACING_SERVICE_NAME" default:"songs"`
+	}
+
+	// Структура для настройки аутентификации по JWT
+	Auth struct {
+		// Издатель токенов, записывается в поле iss и проверяется при входе
+		Issuer string `envconfig:"AUTH_ISSUER" default:"songs"`
+		// Срок действия выпускаемых токенов
+		TokenTTL time.Duration `envconfig:"AUTH_TOKEN_TTL" default:"12h"`
+		// Разрешить чтение без токена. Если выключено, для запросов на чтение нужна роль reader
+		PublicRead bool `envconfig:"AUTH_PUBLIC_READ" default:"true"`
+	}
+
+	// Структура для ограничения частоты запросов клиентов (token bucket).
+	// Лимиты задаются отдельно для групп маршрутов чтения, изменения и администрирования:
+	// Rate — запросов в секунду в среднем, Burst — сколько запросов можно сделать подряд.
+	// Нулевой Rate снимает ограничение с группы.
+	// Лимит IP действует до аутентификации и защищает от перебора ключей и токенов,
+	// каждая проверка ключа API которых обходится запросом к базе
+	RateLimit struct {
+		Enabled    bool    `envconfig:"RATE_LIMIT_ENABLED" default:"true"`
+		IPRate     float64 `envconfig:"RATE_LIMIT_IP_RATE" default:"50"`
+		IPBurst    int     `envconfig:"RATE_LIMIT_IP_BURST" default:"100"`
+		ReadRate   float64 `envconfig:"RATE_LIMIT_READ_RATE" default:"20"`
+		ReadBurst  int     `envconfig:"RATE_LIMIT_READ_BURST" default:"40"`
+		WriteRate  float64 `envconfig:"RATE_LIMIT_WRITE_RATE" default:"5"`
+		WriteBurst int     `envconfig:"RATE_LIMIT_WRITE_BURST" default:"10"`
+		AdminRate  float64 `envconfig:"RATE_LIMIT_ADMIN_RATE" default:"1"`
+		AdminBurst int     `envconfig:"RATE_LIMIT_ADMIN_BURST" default:"5"`
 	}
 
 	// Структура для конфигурации обменного сервиса
@@ -26,8 +118,53 @@ type Config struct {
 		Address string `envconfig:"EXCHANGE_SERVICE_ADDRESS" required:"true"`
 	}
 
+	// Структура для настройки клиента внешнего API с информацией о песнях
 	ExternalAPI struct {
-		Address string `envconfig:"EXTERNAL_API_ADDRESS" required:"true"`
+		// Адрес внешнего API (обязателен для провайдера http)
+		Address string `envconfig:"EXTERNAL_API_ADDRESS"`
+		// Таймаут одной попытки запроса и таймаут установки соединения
+		Timeout        time.Duration `envconfig:"EXTERNAL_API_TIMEOUT" default:"5s"`
+		ConnectTimeout time.Duration `envconfig:"EXTERNAL_API_CONNECT_TIMEOUT" default:"2s"`
+		// Количество попыток и границы экспоненциальной задержки между ними
+		RetryAttempts int           `envconfig:"EXTERNAL_API_RETRY_ATTEMPTS" default:"3"`
+		RetryInitial  time.Duration `envconfig:"EXTERNAL_API_RETRY_INITIAL" default:"200ms"`
+		RetryMax      time.Duration `envconfig:"EXTERNAL_API_RETRY_MAX" default:"2s"`
+		// Количество неудач подряд до размыкания выключателя и пауза до пробного запроса
+		BreakerThreshold int           `envconfig:"EXTERNAL_API_BREAKER_THRESHOLD" default:"5"`
+		BreakerCooldown  time.Duration `envconfig:"EXTERNAL_API_BREAKER_COOLDOWN" default:"30s"`
+		// Время жизни и максимальное количество записей в кэше ответов
+		CacheTTL  time.Duration `envconfig:"EXTERNAL_API_CACHE_TTL" default:"10m"`
+		CacheSize int           `envconfig:"EXTERNAL_API_CACHE_SIZE" default:"1000"`
+	}
+
+	// Структура для настройки источников информации о песнях
+	SongInfo struct {
+		// Провайдеры через запятую в порядке опроса: http, catalog.
+		// Если провайдер не нашёл песню, запрос передаётся следующему
+		Providers []string `envconfig:"SONG_INFO_PROVIDERS" default:"http"`
+		// Путь к локальному каталогу песен в формате JSON или YAML (для провайдера catalog)
+		CatalogPath string `envconfig:"SONG_INFO_CATALOG_PATH"`
+	}
+
+	// Структура для настройки полнотекстового поиска по тексту песен
+	Search struct {
+		// Конфигурация текстового поиска Postgres по умолчанию: russian, english или simple
+		Language string `envconfig:"SEARCH_LANGUAGE" default:"russian"`
+	}
+
+	// Структура для настройки фонового обогащения песен
+	Enrichment struct {
+		// Количество воркеров, разбирающих очередь
+		Workers int `envconfig:"ENRICHMENT_WORKERS" default:"2"`
+		// Интервал опроса очереди при отсутствии задач
+		PollInterval time.Duration `envconfig:"ENRICHMENT_POLL_INTERVAL" default:"1s"`
+		// Время, на которое воркер захватывает задачу
+		Lease time.Duration `envconfig:"ENRICHMENT_LEASE" default:"1m"`
+		// Количество попыток до отправки задачи в dead letter
+		MaxAttempts int `envconfig:"ENRICHMENT_MAX_ATTEMPTS" default:"5"`
+		// Границы экспоненциальной задержки между попытками
+		RetryInitial time.Duration `envconfig:"ENRICHMENT_RETRY_INITIAL" default:"10s"`
+		RetryMax     time.Duration `envconfig:"ENRICHMENT_RETRY_MAX" default:"10m"`
 	}
 }
 
@@ -45,6 +182,14 @@ type Postgres struct {
 	SSLMode string `envconfig:"DB_SSLMODE" default:"disable"`
 	// Пароль для подключения к базе данных (обязателен)
 	Password string `envconfig:"DB_PASSWORD" required:"true"`
+	// Применять миграции при запуске сервера. Если выключено, схема обновляется
+	// командой migrate up, а /readyz сообщает о неготовности, пока версия схемы отстаёт
+	AutoMigrate bool `envconfig:"DB_AUTO_MIGRATE" default:"true"`
+	// Ограничения времени выполнения запросов к базе: на чтение, на изменение и на полнотекстовый поиск.
+	// Нулевое значение снимает ограничение
+	ReadTimeout   time.Duration `envconfig:"DB_READ_TIMEOUT" default:"5s"`
+	WriteTimeout  time.Duration `envconfig:"DB_WRITE_TIMEOUT" default:"10s"`
+	SearchTimeout time.Duration `envconfig:"DB_SEARCH_TIMEOUT" default:"10s"`
 }
 
 // Функция New загружает конфигурацию из переменных окружения и возвращает структуру Config
@@ -66,6 +211,30 @@ func New() (*Config, error) {
 		return nil, err
 	}
 
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+
 	// Возвращаем структуру конфигурации
 	return cfg, nil
 }
+
+// validate проверяет сочетания значений, которые нельзя выразить тегами envconfig
+func (c *Config) validate() error {
+	// Корзина нулевой ёмкости отклоняла бы все запросы группы
+	for _, limit := range []struct {
+		name  string
+		rate  float64
+		burst int
+	}{
+		{"IP", c.RateLimit.IPRate, c.RateLimit.IPBurst},
+		{"READ", c.RateLimit.ReadRate, c.RateLimit.ReadBurst},
+		{"WRITE", c.RateLimit.WriteRate, c.RateLimit.WriteBurst},
+		{"ADMIN", c.RateLimit.AdminRate, c.RateLimit.AdminBurst},
+	} {
+		if limit.rate > 0 && limit.burst < 1 {
+			return fmt.Errorf("RATE_LIMIT_%s_BURST должен быть не меньше 1, если задан RATE_LIMIT_%s_RATE", limit.name, limit.name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+package config
+
+import "testing"
+
+func TestValidateRateLimitBurst(t *testing.T) {
+	tests := []struct {
+		name    string
+		rate    float64
+		burst   int
+		wantErr bool
+	}{
+		{"лимит с корзиной", 5, 10, false},
+		{"лимит снят", 0, 0, false},
+		{"нулевая корзина", 5, 0, true},
+		{"отрицательная корзина", 5, -1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{}
+			cfg.RateLimit.WriteRate = tt.rate
+			cfg.RateLimit.WriteBurst = tt.burst
+			if err := cfg.validate(); (err != nil) != tt.wantErr {
+				t.Fatalf("validate() = %v, ожидается ошибка: %v", err, tt.wantErr)
+			}
+		})
+	}
+}
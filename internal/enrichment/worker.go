@@ -0,0 +1,167 @@
+package enrichment
+
+import (
+	"context"
+	"errors"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"songs/internal/songinfo"
+	"songs/internal/storages"
+	"songs/internal/tracing"
+	"songs/pkg/resilience"
+	"sync"
+	"time"
+)
+
+// Options параметры пула воркеров обогащения
+type Options struct {
+	// Количество параллельно работающих воркеров
+	Workers int
+	// Интервал опроса очереди, когда в ней нет готовых задач
+	PollInterval time.Duration
+	// Время, на которое задача захватывается воркером
+	Lease time.Duration
+	// Количество попыток, после которого задача отправляется в dead letter
+	MaxAttempts int
+	// Задержка между попытками растёт экспоненциально от Initial до Max
+	Backoff resilience.Backoff
+}
+
+// Pool фоновые воркеры, которые разбирают очередь задач обогащения из Postgres
+// и заполняют дату выхода, ссылку и текст песни
+type Pool struct {
+	queue    storages.EnrichmentQueue
+	provider songinfo.SongInfoProvider
+	logger   *logrus.Logger
+	opts     Options
+
+	wg     sync.WaitGroup
+	cancel context.CancelFunc
+}
+
+func NewPool(queue storages.EnrichmentQueue, provider songinfo.SongInfoProvider, logger *logrus.Logger, opts Options) *Pool {
+	if opts.Workers < 1 {
+		opts.Workers = 1
+	}
+	if opts.MaxAttempts < 1 {
+		opts.MaxAttempts = 1
+	}
+	return &Pool{
+		queue:    queue,
+		provider: provider,
+		logger:   logger,
+		opts:     opts,
+	}
+}
+
+// Start запускает воркеры. Они работают до отмены ctx или вызова Stop
+func (p *Pool) Start(ctx context.Context) {
+	ctx, p.cancel = context.WithCancel(ctx)
+	for i := 0; i < p.opts.Workers; i++ {
+		p.wg.Add(1)
+		go p.run(ctx, i+1)
+	}
+	p.logger.Infof("Запущено воркеров обогащения: %d", p.opts.Workers)
+}
+
+// Stop останавливает воркеры и дожидается завершения текущих задач
+func (p *Pool) Stop() {
+	if p.cancel != nil {
+		p.cancel()
+	}
+	p.wg.Wait()
+}
+
+func (p *Pool) run(ctx context.Context, worker int) {
+	defer p.wg.Done()
+
+	ticker := time.NewTicker(p.opts.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		// Разбираем очередь, пока в ней есть готовые задачи, затем ждём следующего опроса
+		for ctx.Err() == nil && p.processNext(ctx, worker) {
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// processNext выполняет одну задачу и сообщает, была ли задача в очереди
+func (p *Pool) processNext(ctx context.Context, worker int) bool {
+	job, err := p.queue.ClaimEnrichmentJob(ctx, p.opts.Lease)
+	if errors.Is(err, storages.ErrNotFound) || ctx.Err() != nil {
+		return false
+	}
+	if err != nil {
+		p.logger.Errorf("Воркер %d: не удалось получить задачу обогащения: %v", worker, err)
+		return false
+	}
+
+	p.logger.Infof("Воркер %d: обогащение песни ID=%d (%s - %s), попытка %d", worker, job.SongID, job.Group, job.Song, job.Attempts)
+
+	// Задача открывает собственную трассу: в неё попадают запрос к внешнему API и запись результата
+	ctx, span := otel.Tracer(tracing.Name).Start(ctx, "enrichment.job", trace.WithAttributes(
+		attribute.Int("song.id", job.SongID),
+		attribute.Int("enrichment.attempt", job.Attempts),
+	))
+	defer span.End()
+
+	err = p.enrich(ctx, job)
+	if err == nil {
+		p.logger.Infof("Воркер %d: песня ID=%d успешно обогащена", worker, job.SongID)
+		return true
+	}
+	if errors.Is(err, storages.ErrJobReleased) {
+		// Задачу перезапустили, передали другому воркеру или песня удалена: результат этой попытки не нужен
+		p.logger.Infof("Воркер %d: результат обогащения песни ID=%d отброшен: %v", worker, job.SongID, err)
+		return true
+	}
+	span.RecordError(err)
+	if ctx.Err() != nil {
+		// Задача останется захваченной до истечения аренды и будет подхвачена после перезапуска
+		return false
+	}
+
+	// Промах во всех провайдерах не исправится повторами, поэтому задача сразу уходит в dead letter
+	if errors.Is(err, songinfo.ErrNotFound) || job.Attempts >= p.opts.MaxAttempts {
+		p.logger.Warnf("Воркер %d: обогащение песни ID=%d прекращено после %d попыток: %v", worker, job.SongID, job.Attempts, err)
+		if err := p.queue.DeadLetterEnrichmentJob(ctx, job, err.Error()); err != nil && !errors.Is(err, storages.ErrJobReleased) {
+			p.logger.Errorf("Воркер %d: не удалось закрыть задачу обогащения ID=%d: %v", worker, job.ID, err)
+		}
+		return true
+	}
+
+	runAt := time.Now().Add(p.opts.Backoff.Delay(job.Attempts))
+	p.logger.Warnf("Воркер %d: ошибка обогащения песни ID=%d, повтор в %s: %v", worker, job.SongID, runAt.Format(time.RFC3339), err)
+	if err := p.queue.RescheduleEnrichmentJob(ctx, job, err.Error(), runAt); err != nil {
+		p.logger.Errorf("Воркер %d: не удалось перенести задачу обогащения ID=%d: %v", worker, job.ID, err)
+	}
+	return true
+}
+
+func (p *Pool) enrich(ctx context.Context, job storages.EnrichmentJob) error {
+	detail, err := p.provider.GetSongInfo(ctx, job.Group, job.Song)
+	if err != nil {
+		return err
+	}
+
+	// Внешние источники могут отдавать дату в формате DD.MM.YYYY, в базе хранится ISO
+	if detail.ReleaseDate != "" {
+		date, err := storages.ParseReleaseDate(detail.ReleaseDate)
+		if err != nil {
+			p.logger.Warnf("Дата выхода песни ID=%d не сохранена: %v", job.SongID, err)
+			detail.ReleaseDate = ""
+		} else {
+			detail.ReleaseDate = date.Format("2006-01-02")
+		}
+	}
+
+	return p.queue.CompleteEnrichmentJob(ctx, job, *detail)
+}
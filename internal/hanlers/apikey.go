@@ -0,0 +1,148 @@
+package hanlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"songs/internal/auth"
+	"songs/internal/storages"
+	"strings"
+	"time"
+)
+
+// CreateAPIKeyRequest параметры нового ключа API
+type CreateAPIKeyRequest struct {
+	// Название, по которому ключ можно узнать, например имя скрипта
+	Name string `json:"name"`
+	// Области доступа: read, write, admin
+	Scopes []string `json:"scopes"`
+	// Момент истечения в формате RFC 3339. Без него ключ бессрочный
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// CreatedAPIKey созданный ключ API вместе с самим ключом, который показывается только один раз
+type CreatedAPIKey struct {
+	storages.APIKey
+	Key string `json:"key"`
+}
+
+// CreateAPIKey
+// @Summary Создать ключ API
+// @Description Выпустить ключ API для машинного клиента. Ключ передаётся в заголовке X-API-Key.
+// @Description Ключ возвращается только в этом ответе, сервис хранит лишь его хэш
+// @Tags Ключи API
+// @Param key body CreateAPIKeyRequest true "Параметры ключа"
+// @Success 201 {object} CreatedAPIKey
+// @Failure 400 {object} problem.Problem "Тело запроса не является JSON"
+// @Failure 422 {object} problem.Problem "Неверные параметры ключа"
+// @Failure 401 {object} problem.Problem "Требуется аутентификация"
+// @Failure 403 {object} problem.Problem "Недостаточно прав"
+// @Failure 500 {object} problem.Problem "Не удалось создать ключ"
+// @Failure 504 {object} problem.Problem "Превышено время ожидания ответа базы данных"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /admin/api-keys [post]
+func (h *Handler) CreateAPIKey(c *gin.Context) {
+	var req CreateAPIKeyRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	var errs storages.ValidationErrors
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		errs = append(errs, &storages.ValidationError{Field: "name", Message: "значение не может быть пустым"})
+	}
+	scopes, err := auth.ParseScopes(req.Scopes)
+	if err != nil {
+		errs = append(errs, &storages.ValidationError{Field: "scopes", Message: err.Error()})
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		errs = append(errs, &storages.ValidationError{Field: "expires_at", Message: "срок действия ключа уже истёк"})
+	}
+	if len(errs) > 0 {
+		h.respondValidationError(c, errs)
+		return
+	}
+
+	key, prefix, hash, err := auth.GenerateAPIKey()
+	if err != nil {
+		h.respondError(c, err, "", "Не удалось создать ключ")
+		return
+	}
+
+	created, err := h.keys.CreateAPIKey(c.Request.Context(), storages.APIKey{
+		Name:      req.Name,
+		Prefix:    prefix,
+		Scopes:    scopes,
+		CreatedBy: actor(c),
+		ExpiresAt: req.ExpiresAt,
+	}, hash)
+	if err != nil {
+		h.respondError(c, err, "", "Не удалось создать ключ")
+		return
+	}
+
+	h.log(c).Infof("Создан ключ API с ID=%d (%s), области: %v", created.ID, created.Name, created.Scopes)
+	c.JSON(http.StatusCreated, CreatedAPIKey{APIKey: created, Key: key})
+}
+
+// GetAPIKeys
+// @Summary Получить список ключей API
+// @Description Получить ключи API с областями доступа, сроком действия и временем последнего использования
+// @Tags Ключи API
+// @Param page query int false "Номер страницы" default(1)
+// @Param limit query int false "Количество элементов на странице" default(20)
+// @Success 200 {array} storages.APIKey
+// @Failure 401 {object} problem.Problem "Требуется аутентификация"
+// @Failure 403 {object} problem.Problem "Недостаточно прав"
+// @Failure 500 {object} problem.Problem "Не удалось получить ключи"
+// @Failure 504 {object} problem.Problem "Превышено время ожидания ответа базы данных"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /admin/api-keys [get]
+func (h *Handler) GetAPIKeys(c *gin.Context) {
+	p := newParams(c)
+	page, limit := p.page(20)
+	if !p.valid() {
+		return
+	}
+
+	keys, err := h.keys.GetAPIKeys(c.Request.Context(), page, limit)
+	if err != nil {
+		h.respondError(c, err, "", "Не удалось получить ключи")
+		return
+	}
+
+	c.JSON(http.StatusOK, keys)
+}
+
+// RevokeAPIKey
+// @Summary Отозвать ключ API
+// @Description Отозвать ключ API. Запросы с отозванным ключом отклоняются сразу
+// @Tags Ключи API
+// @Param id path int true "ID ключа"
+// @Success 204 "Ключ отозван"
+// @Failure 401 {object} problem.Problem "Требуется аутентификация"
+// @Failure 403 {object} problem.Problem "Недостаточно прав"
+// @Failure 404 {object} problem.Problem "Ключ не найден или уже отозван"
+// @Failure 500 {object} problem.Problem "Не удалось отозвать ключ"
+// @Failure 504 {object} problem.Problem "Превышено время ожидания ответа базы данных"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /admin/api-keys/{id} [delete]
+func (h *Handler) RevokeAPIKey(c *gin.Context) {
+	p := newParams(c)
+	id := p.id("id")
+	if !p.valid() {
+		return
+	}
+
+	err := h.keys.RevokeAPIKey(c.Request.Context(), id)
+	if err != nil {
+		h.respondError(c, err, "Ключ не найден или уже отозван", "Не удалось отозвать ключ")
+		return
+	}
+
+	h.log(c).Infof("Ключ API с ID=%d отозван пользователем %s", id, actor(c))
+	c.Status(http.StatusNoContent)
+}
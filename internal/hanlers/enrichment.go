@@ -0,0 +1,64 @@
+package hanlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// GetEnrichment
+// @Summary Получить состояние обогащения песни
+// @Description Получить задачу фонового обогащения песни: статус, количество попыток и последнюю ошибку
+// @Tags Обогащение
+// @Param id path int true "ID песни"
+// @Success 200 {object} storages.EnrichmentJob
+// @Failure 404 {object} problem.Problem "Задача обогащения не найдена"
+// @Failure 500 {object} problem.Problem "Не удалось получить задачу обогащения"
+// @Failure 504 {object} problem.Problem "Превышено время ожидания ответа базы данных"
+// @Router /song/{id}/enrichment [get]
+func (h *Handler) GetEnrichment(c *gin.Context) {
+	p := newParams(c)
+	id := p.id("id")
+	if !p.valid() {
+		return
+	}
+
+	job, err := h.storage.GetEnrichmentJob(c.Request.Context(), id)
+	if err != nil {
+		h.respondError(c, err, "Задача обогащения не найдена", "Не удалось получить задачу обогащения")
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+// RetryEnrichment
+// @Summary Перезапустить обогащение песни
+// @Description Повторно поставить песню в очередь фонового обогащения со сброшенным счётчиком попыток
+// @Tags Обогащение
+// @Param id path int true "ID песни"
+// @Success 202 {object} storages.EnrichmentJob
+// @Failure 404 {object} problem.Problem "Песня не найдена"
+// @Failure 500 {object} problem.Problem "Не удалось перезапустить обогащение"
+// @Failure 504 {object} problem.Problem "Превышено время ожидания ответа базы данных"
+// @Failure 401 {object} problem.Problem "Требуется аутентификация"
+// @Failure 403 {object} problem.Problem "Недостаточно прав"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /song/{id}/enrichment [post]
+func (h *Handler) RetryEnrichment(c *gin.Context) {
+	p := newParams(c)
+	id := p.id("id")
+	if !p.valid() {
+		return
+	}
+
+	h.log(c).Infof("Перезапуск обогащения песни с ID=%d", id)
+
+	job, err := h.storage.RetryEnrichment(c.Request.Context(), actor(c), id)
+	if err != nil {
+		h.respondError(c, err, "Песня не найдена", "Не удалось перезапустить обогащение")
+		return
+	}
+
+	c.JSON(http.StatusAccepted, job)
+}
@@ -0,0 +1,51 @@
+package hanlers
+
+import (
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"songs/internal/problem"
+	"songs/internal/storages"
+)
+
+// statusClientClosedRequest код ответа для запроса, клиент которого отключился до получения ответа.
+// Ответ уже никто не прочитает, код нужен для журнала доступа
+const statusClientClosedRequest = 499
+
+// respondError переводит ошибку хранилища в ответ с подходящим кодом:
+// 404 — запись не найдена, 409 — конфликт с существующими данными, 412 — версия не совпадает,
+// 422 — недопустимые значения, 400 — неверный курсор, 504 — истекло время запроса к базе.
+// Если клиент отключился, запрос прерывается без тела ответа. Прочие ошибки считаются внутренними:
+// они записываются в журнал, а клиент получает только текст failure.
+// notFound задаёт текст ответа 404 для конкретного ресурса
+func (h *Handler) respondError(c *gin.Context, err error, notFound string, failure string) {
+	switch {
+	case errors.Is(c.Request.Context().Err(), context.Canceled):
+		h.log(c).Infof("Клиент отключился до завершения запроса (%s %s): %v", c.Request.Method, c.Request.URL.Path, err)
+		c.AbortWithStatus(statusClientClosedRequest)
+	case errors.Is(err, storages.ErrTimeout):
+		h.log(c).Warnf("%s (%s %s): %v", failure, c.Request.Method, c.Request.URL.Path, err)
+		problem.Respond(c, http.StatusGatewayTimeout, "Превышено время ожидания ответа базы данных")
+	case errors.Is(err, storages.ErrNotFound):
+		if notFound == "" {
+			notFound = "Запись не найдена"
+		}
+		problem.Respond(c, http.StatusNotFound, notFound)
+	case errors.Is(err, storages.ErrVersionMismatch):
+		respondPreconditionFailed(c)
+	case errors.Is(err, storages.ErrInvalidCursor):
+		respondInvalidCursor(c)
+	case errors.Is(err, storages.ErrConflict), errors.Is(err, storages.ErrReferenced):
+		problem.Respond(c, http.StatusConflict, err.Error())
+	case errors.Is(err, storages.ErrMissingReference):
+		problem.Respond(c, http.StatusUnprocessableEntity, err.Error())
+	default:
+		if _, ok := storages.AsValidationErrors(err); ok {
+			h.respondValidationError(c, err)
+			return
+		}
+		h.log(c).Errorf("%s (%s %s): %v", failure, c.Request.Method, c.Request.URL.Path, err)
+		problem.Respond(c, http.StatusInternalServerError, failure)
+	}
+}
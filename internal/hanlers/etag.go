@@ -0,0 +1,90 @@
+package hanlers
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"slices"
+	"songs/internal/problem"
+	"songs/internal/storages"
+	"strconv"
+	"strings"
+)
+
+// songETag строит сильный ETag песни по её версии. Версия растёт при любом изменении,
+// которое видно в ответе, включая переименование группы
+func songETag(song storages.Song) string {
+	return fmt.Sprintf(`"%d"`, song.Version)
+}
+
+// ifMatchVersion определяет по заголовку If-Match версию песни id, от которой клиент строит изменение.
+// Заголовок обязателен: без него отвечает 428, чтобы изменения не затирали друг друга молча.
+// "*" не ограничивает версию (возвращается 0). Из списка ETag выбирается тот, что совпадает с текущей версией.
+// Слабые и повреждённые ETag не совпадают ни с одной версией (RFC 7232 требует сильного сравнения).
+// ok=false означает, что ответ уже отправлен
+func (h *Handler) ifMatchVersion(c *gin.Context, id int) (version int, ok bool) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" {
+		respondPreconditionRequired(c)
+		return 0, false
+	}
+	if header == "*" {
+		return 0, true
+	}
+
+	var versions []int
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if !strings.HasPrefix(candidate, `"`) || !strings.HasSuffix(candidate, `"`) {
+			continue
+		}
+		if v, err := strconv.Atoi(strings.Trim(candidate, `"`)); err == nil && v > 0 {
+			versions = append(versions, v)
+		}
+	}
+	switch len(versions) {
+	case 0:
+		respondPreconditionFailed(c)
+		return 0, false
+	case 1:
+		return versions[0], true
+	}
+
+	// Из нескольких ETag подходит только текущий. Хранилище всё равно сверит версию при изменении,
+	// поэтому песня, изменённая после этого чтения, получит 412
+	song, err := h.storage.GetSong(c.Request.Context(), id)
+	if err != nil {
+		h.respondError(c, err, "Песня не найдена", "Не удалось получить песню")
+		return 0, false
+	}
+	if !slices.Contains(versions, song.Version) {
+		respondPreconditionFailed(c)
+		return 0, false
+	}
+	return song.Version, true
+}
+
+// notModified проверяет заголовок If-None-Match. Сравнение слабое, поэтому W/ игнорируется
+func notModified(c *gin.Context, etag string) bool {
+	header := c.GetHeader("If-None-Match")
+	if header == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// respondPreconditionRequired отвечает 428, если изменение песни прислано без If-Match
+func respondPreconditionRequired(c *gin.Context) {
+	problem.Respond(c, http.StatusPreconditionRequired, "Требуется заголовок If-Match с ETag изменяемой версии песни")
+}
+
+// respondPreconditionFailed отвечает 412, если песню изменили после получения клиентом её ETag
+func respondPreconditionFailed(c *gin.Context) {
+	problem.Respond(c, http.StatusPreconditionFailed, "Песня была изменена другим пользователем, получите актуальную версию и повторите запрос")
+}
@@ -0,0 +1,189 @@
+package hanlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"songs/internal/storages"
+	"strings"
+	"unicode/utf8"
+)
+
+// GetGroups
+// @Summary Получить список групп
+// @Description Получить список групп с пагинацией и поиском по названию
+// @Tags Группы
+// @Param name query string false "Фильтр по названию группы"
+// @Param page query int false "Номер страницы" default(1)
+// @Param limit query int false "Количество элементов на странице" default(10)
+// @Success 200 {array} storages.Group
+// @Failure 400 {object} problem.Problem "Неверные параметры запроса"
+// @Failure 500 {object} problem.Problem "Не удалось получить группы"
+// @Failure 504 {object} problem.Problem "Превышено время ожидания ответа базы данных"
+// @Router /groups [get]
+func (h *Handler) GetGroups(c *gin.Context) {
+	name := c.Query("name")
+	p := newParams(c)
+	page, limit := p.page(10)
+	if !p.valid() {
+		return
+	}
+
+	h.log(c).Infof("Получение групп с name=%s, page=%d, limit=%d", name, page, limit)
+
+	groups, err := h.storage.GetGroups(c.Request.Context(), name, page, limit)
+	if err != nil {
+		h.respondError(c, err, "", "Не удалось получить группы")
+		return
+	}
+
+	c.JSON(http.StatusOK, groups)
+}
+
+// GetGroup
+// @Summary Получить группу
+// @Description Получить группу по ее ID
+// @Tags Группы
+// @Param id path int true "ID группы"
+// @Success 200 {object} storages.Group
+// @Failure 404 {object} problem.Problem "Группа не найдена"
+// @Failure 500 {object} problem.Problem "Не удалось получить группу"
+// @Failure 504 {object} problem.Problem "Превышено время ожидания ответа базы данных"
+// @Router /groups/{id} [get]
+func (h *Handler) GetGroup(c *gin.Context) {
+	p := newParams(c)
+	id := p.id("id")
+	if !p.valid() {
+		return
+	}
+
+	group, err := h.storage.GetGroup(c.Request.Context(), id)
+	if err != nil {
+		h.respondError(c, err, "Группа не найдена", "Не удалось получить группу")
+		return
+	}
+
+	c.JSON(http.StatusOK, group)
+}
+
+// AddGroup
+// @Summary Добавить новую группу
+// @Description Добавить новую группу (исполнителя) в базу данных
+// @Tags Группы
+// @Param group body storages.Group true "Данные о группе"
+// @Success 201 {object} storages.Group
+// @Failure 400 {object} problem.Problem "Неверные данные для добавления группы"
+// @Failure 409 {object} problem.Problem "Группа с таким названием уже существует"
+// @Failure 422 {object} problem.Problem "Недопустимое название группы"
+// @Failure 500 {object} problem.Problem "Не удалось добавить группу"
+// @Failure 504 {object} problem.Problem "Превышено время ожидания ответа базы данных"
+// @Failure 401 {object} problem.Problem "Требуется аутентификация"
+// @Failure 403 {object} problem.Problem "Недостаточно прав"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /groups [post]
+func (h *Handler) AddGroup(c *gin.Context) {
+	var group storages.Group
+	if !bindJSON(c, &group) || !h.validGroup(c, &group) {
+		return
+	}
+
+	id, err := h.storage.AddGroup(c.Request.Context(), group)
+	if err != nil {
+		h.respondError(c, err, "", "Не удалось добавить группу")
+		return
+	}
+	group.ID = id
+
+	h.log(c).Infof("Группа успешно добавлена: %+v", group)
+	c.JSON(http.StatusCreated, group)
+}
+
+// UpdateGroup
+// @Summary Переименовать группу
+// @Description Обновить название группы по ее ID.
+// @Description Версии всех песен группы увеличиваются, а в их журнал изменений записывается действие group_rename
+// @Tags Группы
+// @Param id path int true "ID группы"
+// @Param group body storages.Group true "Данные о группе"
+// @Success 200 {object} storages.Group
+// @Failure 400 {object} problem.Problem "Неверные данные для обновления группы"
+// @Failure 422 {object} problem.Problem "Недопустимое название группы"
+// @Failure 404 {object} problem.Problem "Группа не найдена"
+// @Failure 409 {object} problem.Problem "Группа с таким названием уже существует"
+// @Failure 500 {object} problem.Problem "Не удалось обновить группу"
+// @Failure 504 {object} problem.Problem "Превышено время ожидания ответа базы данных"
+// @Failure 401 {object} problem.Problem "Требуется аутентификация"
+// @Failure 403 {object} problem.Problem "Недостаточно прав"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /groups/{id} [put]
+func (h *Handler) UpdateGroup(c *gin.Context) {
+	p := newParams(c)
+	id := p.id("id")
+	if !p.valid() {
+		return
+	}
+
+	var group storages.Group
+	if !bindJSON(c, &group) || !h.validGroup(c, &group) {
+		return
+	}
+
+	err := h.storage.UpdateGroup(c.Request.Context(), actor(c), id, group)
+	if err != nil {
+		h.respondError(c, err, "Группа не найдена", "Не удалось обновить группу")
+		return
+	}
+	group.ID = id
+
+	h.log(c).Infof("Группа с ID=%d успешно обновлена", id)
+	c.JSON(http.StatusOK, group)
+}
+
+// DeleteGroup
+// @Summary Удалить группу
+// @Description Удалить группу по ее ID
+// @Tags Группы
+// @Param id path int true "ID группы"
+// @Success 200 {object} map[string]interface{} "Группа удалена"
+// @Failure 404 {object} problem.Problem "Группа не найдена"
+// @Failure 409 {object} problem.Problem "У группы есть песни"
+// @Failure 500 {object} problem.Problem "Не удалось удалить группу"
+// @Failure 504 {object} problem.Problem "Превышено время ожидания ответа базы данных"
+// @Failure 401 {object} problem.Problem "Требуется аутентификация"
+// @Failure 403 {object} problem.Problem "Недостаточно прав"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /groups/{id} [delete]
+func (h *Handler) DeleteGroup(c *gin.Context) {
+	p := newParams(c)
+	id := p.id("id")
+	if !p.valid() {
+		return
+	}
+
+	h.log(c).Infof("Попытка удалить группу с ID=%d", id)
+
+	err := h.storage.DeleteGroup(c.Request.Context(), id)
+	if err != nil {
+		h.respondError(c, err, "Группа не найдена", "Не удалось удалить группу")
+		return
+	}
+
+	h.log(c).Infof("Группа с ID=%d удалена пользователем %s", id, actor(c))
+	c.JSON(http.StatusOK, gin.H{"message": "Группа удалена"})
+}
+
+// validGroup проверяет название группы и отвечает 422, если оно пустое или слишком длинное
+func (h *Handler) validGroup(c *gin.Context, group *storages.Group) bool {
+	group.Name = strings.TrimSpace(group.Name)
+	switch {
+	case group.Name == "":
+		h.respondValidationError(c, &storages.ValidationError{Field: "name", Message: "значение не может быть пустым"})
+	case utf8.RuneCountInString(group.Name) > 255:
+		h.respondValidationError(c, &storages.ValidationError{Field: "name", Message: "значение длиннее 255 символов"})
+	default:
+		return true
+	}
+	return false
+}
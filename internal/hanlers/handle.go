@@ -1,21 +1,38 @@
 package hanlers
 
 import (
+	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
+	"songs/internal/auth"
 	"songs/internal/config"
 	"songs/internal/storages"
 )
 
 type Handler struct {
 	storage storages.Storages
+	keys    storages.APIKeyStore
 	logger  *logrus.Logger
 	config  *config.Config
 }
 
-func NewHandler(storage storages.Storages, logger *logrus.Logger, cfg *config.Config) *Handler {
+func NewHandler(storage storages.Storages, keys storages.APIKeyStore, logger *logrus.Logger, cfg *config.Config) *Handler {
 	return &Handler{
 		storage: storage,
+		keys:    keys,
 		logger:  logger,
 		config:  cfg,
 	}
 }
+
+// actor возвращает автора изменения для журнала изменений песен — пользователя из токена или ключ API
+func actor(c *gin.Context) string {
+	if principal, ok := auth.PrincipalFrom(c); ok {
+		return principal.Subject
+	}
+	return "anonymous"
+}
+
+// log возвращает запись журнала, привязанную к запросу, чтобы в неё попал идентификатор запроса
+func (h *Handler) log(c *gin.Context) *logrus.Entry {
+	return h.logger.WithContext(c.Request.Context())
+}
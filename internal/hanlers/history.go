@@ -0,0 +1,86 @@
+package hanlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// GetSongHistory
+// @Summary Получить историю изменений песни
+// @Description Получить журнал изменений песни от новых записей к старым: кто, когда и что изменил,
+// @Description с состоянием песни до и после изменения. История сохраняется и после окончательного удаления песни
+// @Tags История
+// @Param id path int true "ID песни"
+// @Param page query int false "Номер страницы" default(1)
+// @Param limit query int false "Количество записей на странице" default(20)
+// @Success 200 {array} storages.AuditEntry
+// @Failure 404 {object} problem.Problem "Песня не найдена"
+// @Failure 500 {object} problem.Problem "Не удалось получить историю песни"
+// @Failure 504 {object} problem.Problem "Превышено время ожидания ответа базы данных"
+// @Router /song/{id}/history [get]
+func (h *Handler) GetSongHistory(c *gin.Context) {
+	p := newParams(c)
+	id := p.id("id")
+	page, limit := p.page(20)
+	if !p.valid() {
+		return
+	}
+
+	h.log(c).Infof("Получение истории песни с ID=%d, page=%d, limit=%d", id, page, limit)
+
+	entries, err := h.storage.GetSongHistory(c.Request.Context(), id, page, limit)
+	if err != nil {
+		h.respondError(c, err, "Песня не найдена", "Не удалось получить историю песни")
+		return
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
+
+// RevertSong
+// @Summary Откатить песню к ревизии
+// @Description Вернуть группу, название, дату выхода, ссылку и текст песни к состоянию после указанного изменения.
+// @Description Номер ревизии — ID записи из истории песни. Откат сам записывается в историю
+// @Tags История
+// @Param id path int true "ID песни"
+// @Param revision path int true "Номер ревизии"
+// @Param If-Match header string true "ETag версии песни, которую откатывает клиент. Можно перечислить несколько ETag через запятую или указать *"
+// @Success 200 {object} storages.Song
+// @Header 200 {string} ETag "Версия песни"
+// @Failure 400 {object} problem.Problem "Неверный номер ревизии"
+// @Failure 404 {object} problem.Problem "Песня или ревизия не найдена"
+// @Failure 412 {object} problem.Problem "Версия песни не совпадает с If-Match"
+// @Failure 428 {object} problem.Problem "Не передан заголовок If-Match"
+// @Failure 422 {object} problem.Problem "Ревизия не содержит состояния песни"
+// @Failure 500 {object} problem.Problem "Не удалось откатить песню"
+// @Failure 504 {object} problem.Problem "Превышено время ожидания ответа базы данных"
+// @Failure 401 {object} problem.Problem "Требуется аутентификация"
+// @Failure 403 {object} problem.Problem "Недостаточно прав"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /song/{id}/history/{revision}/revert [post]
+func (h *Handler) RevertSong(c *gin.Context) {
+	p := newParams(c)
+	id := p.id("id")
+	revision := int64(p.id("revision"))
+	if !p.valid() {
+		return
+	}
+
+	h.log(c).Infof("Откат песни с ID=%d к ревизии %d", id, revision)
+
+	version, ok := h.ifMatchVersion(c, id)
+	if !ok {
+		return
+	}
+
+	song, err := h.storage.RevertSong(c.Request.Context(), actor(c), id, revision, version)
+	if err != nil {
+		h.respondError(c, err, "Песня или ревизия не найдена", "Не удалось откатить песню")
+		return
+	}
+
+	h.log(c).Infof("Песня с ID=%d откачена к ревизии %d", id, revision)
+	c.Header("ETag", songETag(song))
+	c.JSON(http.StatusOK, song)
+}
@@ -0,0 +1,89 @@
+package hanlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"songs/internal/problem"
+	"songs/internal/storages"
+	"strconv"
+)
+
+// Границы параметров пагинации. Слишком большая страница или смещение
+// превращаются в тяжёлый запрос к базе, поэтому они ограничены
+const (
+	maxPageLimit = 100
+	maxPage      = 100000
+)
+
+// params проверяет параметры пути и запроса и накапливает ошибки по всем параметрам,
+// чтобы клиент получил их одним ответом
+type params struct {
+	c    *gin.Context
+	errs []problem.FieldError
+}
+
+func newParams(c *gin.Context) *params {
+	return &params{c: c}
+}
+
+func (p *params) fail(in string, field string, message string) {
+	p.errs = append(p.errs, problem.FieldError{In: in, Field: field, Message: message})
+}
+
+// id возвращает положительный целый идентификатор из пути
+func (p *params) id(name string) int {
+	id, err := strconv.Atoi(p.c.Param(name))
+	if err != nil || id < 1 {
+		p.fail(problem.InPath, name, "ожидается положительное целое число")
+		return 0
+	}
+	return id
+}
+
+// queryInt возвращает целый параметр запроса в границах [min, max] или def, если параметр не задан
+func (p *params) queryInt(name string, def int, min int, max int) int {
+	value := p.c.Query(name)
+	if value == "" {
+		return def
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		p.fail(problem.InQuery, name, "ожидается целое число")
+		return def
+	}
+	if n < min || n > max {
+		p.fail(problem.InQuery, name, "значение должно быть от "+strconv.Itoa(min)+" до "+strconv.Itoa(max))
+		return def
+	}
+	return n
+}
+
+// page возвращает номер страницы и размер страницы с размером по умолчанию def
+func (p *params) page(def int) (page int, limit int) {
+	return p.queryInt("page", 1, 1, maxPage), p.queryInt("limit", def, 1, maxPageLimit)
+}
+
+// valid отвечает 400 со списком ошибок, если хотя бы один параметр неверен
+func (p *params) valid() bool {
+	if len(p.errs) == 0 {
+		return true
+	}
+	problem.Respond(p.c, http.StatusBadRequest, "Неверные параметры запроса", p.errs...)
+	return false
+}
+
+// bindJSON разбирает тело запроса в v и отвечает 400, если это не JSON нужной структуры.
+// Текст ошибки разбора не возвращается клиенту, так как содержит имена внутренних типов
+func bindJSON(c *gin.Context, v any) bool {
+	if err := c.ShouldBindJSON(v); err != nil {
+		problem.Respond(c, http.StatusBadRequest, "Тело запроса должно быть JSON-объектом с полями нужных типов")
+		return false
+	}
+	return true
+}
+
+// respondInvalidCursor отвечает 400 на повреждённый курсор пагинации или курсор от другой сортировки
+func respondInvalidCursor(c *gin.Context) {
+	problem.Respond(c, http.StatusBadRequest, "Неверные параметры запроса",
+		problem.FieldError{In: problem.InQuery, Field: "cursor", Message: storages.ErrInvalidCursor.Error()})
+}
@@ -0,0 +1,61 @@
+package hanlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"songs/internal/storages"
+)
+
+// songPatchFields поля песни, которые можно изменить через PATCH, и признак допустимости null.
+// null в JSON Merge Patch (RFC 7396) означает удаление значения, поэтому он разрешён только для необязательных полей
+var songPatchFields = map[string]bool{
+	"group":       false,
+	"song":        false,
+	"releaseDate": true,
+	"link":        true,
+}
+
+// parseSongMergePatch разбирает тело запроса в формате JSON Merge Patch.
+// Неизвестные и доступные только для чтения поля, а также значения неверного типа отклоняются
+func parseSongMergePatch(body []byte) (storages.SongPatch, error) {
+	var patch storages.SongPatch
+
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(body, &doc); err != nil || doc == nil {
+		return patch, fmt.Errorf("тело запроса должно быть JSON-объектом")
+	}
+
+	for field, raw := range doc {
+		nullable, ok := songPatchFields[field]
+		if !ok {
+			return patch, &storages.ValidationError{Field: field, Message: "поле не существует или доступно только для чтения"}
+		}
+
+		var value *string
+		if bytes.Equal(bytes.TrimSpace(raw), []byte("null")) {
+			if !nullable {
+				return patch, &storages.ValidationError{Field: field, Message: "обязательное поле нельзя удалить"}
+			}
+			value = new(string)
+		} else {
+			value = new(string)
+			if err := json.Unmarshal(raw, value); err != nil {
+				return patch, &storages.ValidationError{Field: field, Message: "ожидается строка"}
+			}
+		}
+
+		switch field {
+		case "group":
+			patch.Group = value
+		case "song":
+			patch.Name = value
+		case "releaseDate":
+			patch.ReleaseDate = value
+		case "link":
+			patch.Link = value
+		}
+	}
+
+	return patch, patch.Normalize()
+}
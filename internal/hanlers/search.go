@@ -0,0 +1,57 @@
+package hanlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"songs/internal/problem"
+	"songs/internal/storages"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxSearchPhrase максимальная длина поисковой фразы
+const maxSearchPhrase = 500
+
+// SearchLyrics
+// @Summary Поиск песен по тексту
+// @Description Полнотекстовый поиск по тексту песен. Песни упорядочены по релевантности,
+// @Description совпавшие строки возвращаются экранированными для HTML, с выделением <mark></mark>.
+// @Description Поддерживаются фразы в кавычках, OR и исключение слов через "-"
+// @Tags Поиск
+// @Param q query string true "Поисковая фраза"
+// @Param lang query string false "Язык поиска: russian, english, simple (по умолчанию из конфигурации)"
+// @Param page query int false "Номер страницы" default(1)
+// @Param limit query int false "Количество песен на странице" default(10)
+// @Success 200 {array} storages.SearchResult
+// @Failure 400 {object} problem.Problem "Неверные параметры запроса"
+// @Failure 500 {object} problem.Problem "Не удалось выполнить поиск"
+// @Failure 504 {object} problem.Problem "Превышено время ожидания ответа базы данных"
+// @Router /search [get]
+func (h *Handler) SearchLyrics(c *gin.Context) {
+	phrase := strings.TrimSpace(c.Query("q"))
+	lang := c.DefaultQuery("lang", h.config.Search.Language)
+	p := newParams(c)
+	page, limit := p.page(10)
+	if phrase == "" {
+		p.fail(problem.InQuery, "q", "параметр обязателен")
+	} else if utf8.RuneCountInString(phrase) > maxSearchPhrase {
+		p.fail(problem.InQuery, "q", "поисковая фраза длиннее "+strconv.Itoa(maxSearchPhrase)+" символов")
+	}
+	if !storages.IsSearchLanguage(lang) {
+		p.fail(problem.InQuery, "lang", "поддерживаемые языки: "+strings.Join(storages.SearchLanguages, ", "))
+	}
+	if !p.valid() {
+		return
+	}
+
+	h.log(c).Infof("Поиск по тексту песен q=%s, lang=%s, page=%d, limit=%d", phrase, lang, page, limit)
+
+	results, err := h.storage.SearchLyrics(c.Request.Context(), phrase, lang, page, limit)
+	if err != nil {
+		h.respondError(c, err, "", "Не удалось выполнить поиск")
+		return
+	}
+
+	c.JSON(http.StatusOK, results)
+}
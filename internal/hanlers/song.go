@@ -1,66 +1,156 @@
 package hanlers
 
 import (
-	"encoding/json"
 	"fmt"
 	"github.com/gin-gonic/gin"
+	"math"
 	"net/http"
+	"songs/internal/problem"
 	"songs/internal/storages"
-	"strconv"
+	"time"
 )
 
 // GetSongs
 // @Summary Получить список песен
-// @Description Получить список песен с пагинацией, отфильтрованный по названию группы и песни
+// @Description Получить список песен с пагинацией, фильтрацией по любому полю песни и сортировкой.
+// @Description Строковые фильтры не учитывают регистр, режим сопоставления задаётся параметром *_match
 // @Tags Песни
+// @Param id query int false "Фильтр по ID песни"
+// @Param group_id query int false "Фильтр по ID группы"
 // @Param group query string false "Фильтр по названию группы"
+// @Param group_match query string false "Режим сопоставления группы: contains, exact, prefix" default(contains)
 // @Param song query string false "Фильтр по названию песни"
+// @Param song_match query string false "Режим сопоставления песни: contains, exact, prefix" default(contains)
+// @Param link query string false "Фильтр по ссылке"
+// @Param link_match query string false "Режим сопоставления ссылки: contains, exact, prefix" default(contains)
+// @Param release_date query string false "Точная дата выхода (YYYY-MM-DD или DD.MM.YYYY)"
+// @Param release_date_from query string false "Дата выхода не раньше (включительно)"
+// @Param release_date_to query string false "Дата выхода не позже (включительно)"
+// @Param enrichment_status query string false "Статус обогащения: pending, done, failed"
+// @Param version query int false "Фильтр по версии песни"
+// @Param updated_at_from query string false "Изменена не раньше (RFC 3339, включительно)"
+// @Param updated_at_to query string false "Изменена не позже (RFC 3339, включительно)"
+// @Param sort query string false "Поля сортировки через запятую, минус означает убывание, например -release_date,song. Допустимы id, group_id, group, song, release_date, link, enrichment_status, version, updated_at, deleted_at"
 // @Param page query int false "Номер страницы" default(1)
 // @Param limit query int false "Количество элементов на странице" default(10)
-// @Success 200 {array} storages.Song
-// @Failure 400 {object} map[string]interface{} "Неверные параметры запроса"
-// @Failure 500 {object} map[string]interface{} "Не удалось получить песни"
+// @Param cursor query string false "Курсор next или prev из предыдущего ответа, при наличии курсора page игнорируется"
+// @Success 200 {object} storages.SongsPage
+// @Failure 400 {object} problem.Problem "Неверные параметры запроса"
+// @Failure 500 {object} problem.Problem "Не удалось получить песни"
+// @Failure 504 {object} problem.Problem "Превышено время ожидания ответа базы данных"
 // @Router /songs [get]
 func (h *Handler) GetSongs(c *gin.Context) {
-	group := c.Query("group")
-	song := c.Query("song")
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	p := newParams(c)
+	page, limit := p.page(10)
+	cursor := c.Query("cursor")
+	filter := p.songFilter()
+	if !p.valid() {
+		return
+	}
 
-	h.logger.Infof("Получение песен с group=%s, song=%s, page=%d, limit=%d", group, song, page, limit)
+	h.log(c).Infof("Получение песен с filter=%+v, page=%d, limit=%d, cursor=%s", filter, page, limit, cursor)
 
-	songs, err := h.storage.GetSongs(group, song, page, limit)
+	songs, err := h.storage.GetSongs(c.Request.Context(), filter, storages.Pagination{Page: page, Limit: limit, Cursor: cursor})
 	if err != nil {
-		h.logger.Errorf("Не удалось получить песни: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось получить песни", "details": err.Error()})
+		h.respondError(c, err, "", "Не удалось получить песни")
 		return
 	}
 
 	c.JSON(http.StatusOK, songs)
 }
 
+// songFilter собирает фильтр списка песен из параметров запроса
+func (p *params) songFilter() storages.SongFilter {
+	var filter storages.SongFilter
+
+	filter.ID = p.queryInt("id", 0, 1, math.MaxInt32)
+	filter.GroupID = p.queryInt("group_id", 0, 1, math.MaxInt32)
+	filter.Version = p.queryInt("version", 0, 1, math.MaxInt32)
+
+	for _, f := range []struct {
+		name   string
+		target *storages.TextFilter
+	}{{"group", &filter.Group}, {"song", &filter.Song}, {"link", &filter.Link}} {
+		f.target.Value = p.c.Query(f.name)
+		match, err := storages.ParseMatch(p.c.Query(f.name + "_match"))
+		if err != nil {
+			p.fail(problem.InQuery, f.name+"_match", err.Error())
+		}
+		f.target.Match = match
+	}
+
+	// Даты приводятся к ISO, чтобы в запрос к базе попадало проверенное значение
+	for _, f := range []struct {
+		name   string
+		target *string
+	}{{"release_date", &filter.ReleaseDate}, {"release_date_from", &filter.ReleaseDateFrom}, {"release_date_to", &filter.ReleaseDateTo}} {
+		if value := p.c.Query(f.name); value != "" {
+			date, err := storages.ParseReleaseDate(value)
+			if err != nil {
+				p.fail(problem.InQuery, f.name, err.Error())
+				continue
+			}
+			*f.target = date.Format("2006-01-02")
+		}
+	}
+
+	for _, f := range []struct {
+		name   string
+		target *time.Time
+	}{{"updated_at_from", &filter.UpdatedFrom}, {"updated_at_to", &filter.UpdatedTo}} {
+		if value := p.c.Query(f.name); value != "" {
+			updated, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				p.fail(problem.InQuery, f.name, "ожидается время в формате RFC 3339, например 2024-01-31T15:04:05Z")
+				continue
+			}
+			*f.target = updated
+		}
+	}
+
+	switch status := p.c.Query("enrichment_status"); status {
+	case "", storages.EnrichmentPending, storages.EnrichmentDone, storages.EnrichmentFailed:
+		filter.EnrichmentStatus = status
+	default:
+		p.fail(problem.InQuery, "enrichment_status", "допустимые значения: pending, done, failed")
+	}
+
+	sort, err := storages.ParseSort(p.c.Query("sort"))
+	if err != nil {
+		p.fail(problem.InQuery, "sort", err.Error())
+	}
+	filter.Sort = sort
+	return filter
+}
+
 // GetLyrics
 // @Summary Получить текст песни
-// @Description Получить текст песни с пагинацией для конкретной песни
+// @Description Получить текст песни с пагинацией по куплетам для конкретной песни
 // @Tags Тексты песен
 // @Param id path int true "ID песни"
 // @Param page query int false "Номер страницы" default(1)
-// @Param limit query int false "Количество элементов на странице" default(1)
-// @Success 200 {array} string
-// @Failure 400 {object} map[string]interface{} "Неверные параметры запроса"
-// @Failure 500 {object} map[string]interface{} "Не удалось получить текст песни"
-// @Router /songs/{id}/lyrics [get]
+// @Param limit query int false "Количество куплетов на странице" default(1)
+// @Param cursor query string false "Курсор next или prev из предыдущего ответа, при наличии курсора page игнорируется"
+// @Success 200 {object} storages.VersesPage
+// @Failure 400 {object} problem.Problem "Неверные параметры запроса"
+// @Failure 404 {object} problem.Problem "Песня не найдена"
+// @Failure 500 {object} problem.Problem "Не удалось получить текст песни"
+// @Failure 504 {object} problem.Problem "Превышено время ожидания ответа базы данных"
+// @Router /song/{id}/lyrics [get]
 func (h *Handler) GetLyrics(c *gin.Context) {
-	id, _ := strconv.Atoi(c.Param("id"))
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "1"))
+	p := newParams(c)
+	id := p.id("id")
+	page, limit := p.page(1)
+	cursor := c.Query("cursor")
+	if !p.valid() {
+		return
+	}
 
-	h.logger.Infof("Получение текста песни с ID=%d, page=%d, limit=%d", id, page, limit)
+	h.log(c).Infof("Получение текста песни с ID=%d, page=%d, limit=%d, cursor=%s", id, page, limit, cursor)
 
-	lyrics, err := h.storage.GetLyrics(id, page, limit)
+	lyrics, err := h.storage.GetLyrics(c.Request.Context(), id, storages.Pagination{Page: page, Limit: limit, Cursor: cursor})
 	if err != nil {
-		h.logger.Errorf("Не удалось получить текст песни с ID=%d: %v", id, err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось получить текст песни", "details": err.Error()})
+		h.respondError(c, err, "Песня не найдена", "Не удалось получить текст песни")
 		return
 	}
 
@@ -69,133 +159,259 @@ func (h *Handler) GetLyrics(c *gin.Context) {
 
 // DeleteSong
 // @Summary Удалить песню
-// @Description Удалить песню по ее ID
+// @Description Переместить песню в корзину по ее ID. Песню можно восстановить, пока она не удалена из корзины окончательно
 // @Tags Песни
 // @Param id path int true "ID песни"
-// @Success 200 {object} map[string]interface{} "Песня удалена"
-// @Failure 400 {object} map[string]interface{} "Неверный ID песни"
-// @Failure 404 {object} map[string]interface{} "Песня не найдена"
-// @Failure 500 {object} map[string]interface{} "Не удалось удалить песню"
-// @Router /songs/{id} [delete]
-
+// @Param If-Match header string true "ETag версии песни, которую удаляет клиент. Можно перечислить несколько ETag через запятую или указать *"
+// @Success 200 {object} map[string]interface{} "Песня перемещена в корзину"
+// @Failure 400 {object} problem.Problem "Неверный ID песни"
+// @Failure 404 {object} problem.Problem "Песня не найдена"
+// @Failure 412 {object} problem.Problem "Версия песни не совпадает с If-Match"
+// @Failure 428 {object} problem.Problem "Не передан заголовок If-Match"
+// @Failure 500 {object} problem.Problem "Не удалось удалить песню"
+// @Failure 504 {object} problem.Problem "Превышено время ожидания ответа базы данных"
+// @Failure 401 {object} problem.Problem "Требуется аутентификация"
+// @Failure 403 {object} problem.Problem "Недостаточно прав"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /song/{id} [delete]
 func (h *Handler) DeleteSong(c *gin.Context) {
-	id, _ := strconv.Atoi(c.Param("id"))
+	p := newParams(c)
+	id := p.id("id")
+	if !p.valid() {
+		return
+	}
 
-	h.logger.Infof("Попытка удалить песню с ID=%d", id)
+	h.log(c).Infof("Попытка удалить песню с ID=%d", id)
+
+	version, ok := h.ifMatchVersion(c, id)
+	if !ok {
+		return
+	}
 
-	err := h.storage.DeleteSong(id)
+	err := h.storage.DeleteSong(c.Request.Context(), actor(c), id, version)
 	if err != nil {
-		h.logger.Errorf("Не удалось удалить песню с ID=%d: %v", id, err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось удалить песню", "details": err.Error()})
+		h.respondError(c, err, "Песня не найдена", "Не удалось удалить песню")
 		return
 	}
 
-	h.logger.Infof("Песня с ID=%d успешно удалена", id)
-	c.JSON(http.StatusOK, gin.H{"message": "Песня удалена"})
+	h.log(c).Infof("Песня с ID=%d перемещена в корзину", id)
+	c.JSON(http.StatusOK, gin.H{"message": "Песня перемещена в корзину"})
 }
 
-// UpdateSongPartial
-// @Summary Частичное обновление информации о песне
-// @Description Обновить одно или несколько свойств песни по ее ID
+// UpdateSong
+// @Summary Обновить информацию о песне
+// @Description Полностью заменить группу, название, дату выхода и ссылку песни по ее ID
 // @Tags Песни
 // @Param id path int true "ID песни"
+// @Param If-Match header string true "ETag версии песни, которую изменяет клиент. Можно перечислить несколько ETag через запятую или указать *"
 // @Param song body storages.Song true "Данные о песне"
-// @Success 200 {object} map[string]interface{} "Успешно"
-// @Failure 400 {object} map[string]interface{} "Неверные данные для обновления песни"
-// @Failure 404 {object} map[string]interface{} "Песня не найдена"
-// @Failure 500 {object} map[string]interface{} "Не удалось обновить песню"
-// @Router /songs/{id}/partial [put]
-func (h *Handler) UpdateSongPartial(c *gin.Context) {
-	id, _ := strconv.Atoi(c.Param("id"))
+// @Success 200 {object} storages.Song
+// @Failure 400 {object} problem.Problem "Неверные данные для обновления песни"
+// @Failure 404 {object} problem.Problem "Песня не найдена"
+// @Failure 412 {object} problem.Problem "Версия песни не совпадает с If-Match"
+// @Failure 428 {object} problem.Problem "Не передан заголовок If-Match"
+// @Failure 422 {object} problem.Problem "Недопустимое значение поля"
+// @Failure 500 {object} problem.Problem "Не удалось обновить песню"
+// @Failure 504 {object} problem.Problem "Превышено время ожидания ответа базы данных"
+// @Failure 401 {object} problem.Problem "Требуется аутентификация"
+// @Failure 403 {object} problem.Problem "Недостаточно прав"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /song/{id} [put]
+func (h *Handler) UpdateSong(c *gin.Context) {
+	p := newParams(c)
+	id := p.id("id")
+	if !p.valid() {
+		return
+	}
 
-	var updates map[string]interface{}
-	if err := c.ShouldBindJSON(&updates); err != nil {
-		h.logger.Errorf("Неверные данные для обновления песни с ID=%d: %v", id, err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверные данные", "details": err.Error()})
+	var song storages.Song
+	if !bindJSON(c, &song) {
 		return
 	}
 
-	h.logger.Infof("Обновление песни с ID=%d, изменения=%+v", id, updates)
+	patch := storages.SongPatch{Group: &song.Group, Name: &song.Name, ReleaseDate: &song.ReleaseDate, Link: &song.Link}
+	if err := patch.Normalize(); err != nil {
+		h.respondValidationError(c, err)
+		return
+	}
+
+	h.log(c).Infof("Обновление песни с ID=%d: %+v", id, song)
+
+	version, ok := h.ifMatchVersion(c, id)
+	if !ok {
+		return
+	}
 
-	err := h.storage.UpdateSongPartial(id, updates)
+	updated, err := h.storage.UpdateSong(c.Request.Context(), actor(c), id, song, version)
 	if err != nil {
-		h.logger.Errorf("Не удалось обновить песню с ID=%d: %v", id, err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось обновить песню", "details": err.Error()})
+		h.respondError(c, err, "Песня не найдена", "Не удалось обновить песню")
 		return
 	}
 
-	h.logger.Infof("Песня с ID=%d успешно обновлена", id)
-	c.JSON(http.StatusOK, gin.H{"message": "Песня обновлена"})
+	h.log(c).Infof("Песня с ID=%d успешно обновлена", id)
+	c.Header("ETag", songETag(updated))
+	c.JSON(http.StatusOK, updated)
 }
 
-// AddSong
-// @Summary Добавить новую песню
-// @Description Добавить новую песню в базу данных
+// UpdateSongPartial
+// @Summary Частичное обновление информации о песне
+// @Description Обновить одно или несколько свойств песни по ее ID в формате JSON Merge Patch (RFC 7396).
+// @Description Изменяемые поля: group, song, releaseDate (YYYY-MM-DD или DD.MM.YYYY), link (http/https URL).
+// @Description null удаляет значение releaseDate или link
 // @Tags Песни
-// @Param song body storages.Song true "Данные о песне"
-// @Success 201 {object} map[string]interface{} "Песня добавлена"
-// @Failure 400 {object} map[string]interface{} "Неверные данные для добавления песни"
-// @Failure 500 {object} map[string]interface{} "Не удалось добавить песню"
-// @Router /songs [post]
-func (h *Handler) AddSong(c *gin.Context) {
-	var song storages.Song
-	if err := c.ShouldBindJSON(&song); err != nil {
-		h.logger.Errorf("Неверные данные для добавления новой песни: %v", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверные данные", "details": err.Error()})
+// @Accept json
+// @Accept application/merge-patch+json
+// @Param id path int true "ID песни"
+// @Param If-Match header string true "ETag версии песни, которую изменяет клиент. Можно перечислить несколько ETag через запятую или указать *"
+// @Param song body storages.Song true "Изменяемые поля песни"
+// @Success 200 {object} storages.Song
+// @Failure 400 {object} problem.Problem "Неверные данные для обновления песни"
+// @Failure 404 {object} problem.Problem "Песня не найдена"
+// @Failure 412 {object} problem.Problem "Версия песни не совпадает с If-Match"
+// @Failure 428 {object} problem.Problem "Не передан заголовок If-Match"
+// @Failure 422 {object} problem.Problem "Неизвестное поле или недопустимое значение"
+// @Failure 500 {object} problem.Problem "Не удалось обновить песню"
+// @Failure 504 {object} problem.Problem "Превышено время ожидания ответа базы данных"
+// @Failure 401 {object} problem.Problem "Требуется аутентификация"
+// @Failure 403 {object} problem.Problem "Недостаточно прав"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /song/{id} [patch]
+func (h *Handler) UpdateSongPartial(c *gin.Context) {
+	p := newParams(c)
+	id := p.id("id")
+	if !p.valid() {
 		return
 	}
 
-	h.logger.Infof("Добавление новой песни: %+v", song)
+	body, err := c.GetRawData()
+	if err != nil {
+		problem.Respond(c, http.StatusBadRequest, "Не удалось прочитать тело запроса")
+		return
+	}
 
-	songDetail, err := h.getSongDetail(song.Group, song.Name)
+	patch, err := parseSongMergePatch(body)
 	if err != nil {
-		h.logger.Errorf("Не удалось получить детали песни с внешнего API: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось получить детали песни", "details": err.Error()})
+		h.log(c).Errorf("Неверные данные для обновления песни с ID=%d: %v", id, err)
+		h.respondValidationError(c, err)
 		return
 	}
 
-	h.logger.Infof("Детали песни с внешнего API: %+v", songDetail)
+	h.log(c).Infof("Частичное обновление песни с ID=%d", id)
 
-	song.ReleaseDate = songDetail.ReleaseDate
-	song.Link = songDetail.Link
+	version, ok := h.ifMatchVersion(c, id)
+	if !ok {
+		return
+	}
 
-	err = h.storage.AddSong(song)
+	updated, err := h.storage.UpdateSongPartial(c.Request.Context(), actor(c), id, patch, version)
 	if err != nil {
-		h.logger.Errorf("Не удалось добавить песню: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось добавить песню", "details": err.Error()})
+		h.respondError(c, err, "Песня не найдена", "Не удалось обновить песню")
 		return
 	}
 
-	for _, line := range songDetail.Text {
-		err = h.storage.AddLyrics(song.ID, line)
-		if err != nil {
-			h.logger.Errorf("Не удалось добавить текст песни: %v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось добавить текст песни", "details": err.Error()})
-			return
+	h.log(c).Infof("Песня с ID=%d успешно обновлена", id)
+	c.Header("ETag", songETag(updated))
+	c.JSON(http.StatusOK, updated)
+}
+
+// respondValidationError отвечает 422 с ошибками полей тела запроса и 400 на прочие ошибки разбора
+func (h *Handler) respondValidationError(c *gin.Context, err error) {
+	if errs, ok := storages.AsValidationErrors(err); ok {
+		fields := make([]problem.FieldError, len(errs))
+		for i, e := range errs {
+			fields[i] = problem.FieldError{In: problem.InBody, Field: e.Field, Message: e.Message}
 		}
+		problem.Respond(c, http.StatusUnprocessableEntity, "Недопустимое значение поля", fields...)
+		return
 	}
-
-	h.logger.Infof("Песня успешно добавлена: %+v", song)
-	c.JSON(http.StatusCreated, gin.H{"message": "Песня добавлена", "details": songDetail})
+	problem.Respond(c, http.StatusBadRequest, err.Error())
 }
 
-func (h *Handler) getSongDetail(group string, song string) (*storages.SongDetail, error) {
-	apiURL := fmt.Sprintf("%s/info?group=%s&song=%s", h.config.ExternalAPI.Address, group, song)
+// GetSong
+// @Summary Получить песню
+// @Description Получить информацию о песне по ее ID
+// @Tags Песни
+// @Param id path int true "ID песни"
+// @Param If-None-Match header string false "ETag ранее полученной версии песни"
+// @Success 200 {object} storages.Song
+// @Success 304 "Песня не изменилась"
+// @Header 200 {string} ETag "Версия песни"
+// @Failure 404 {object} problem.Problem "Песня не найдена"
+// @Failure 500 {object} problem.Problem "Не удалось получить песню"
+// @Failure 504 {object} problem.Problem "Превышено время ожидания ответа базы данных"
+// @Router /song/{id} [get]
+func (h *Handler) GetSong(c *gin.Context) {
+	p := newParams(c)
+	id := p.id("id")
+	if !p.valid() {
+		return
+	}
 
-	resp, err := http.Get(apiURL)
+	song, err := h.storage.GetSong(c.Request.Context(), id)
 	if err != nil {
-		return nil, err
+		h.respondError(c, err, "Песня не найдена", "Не удалось получить песню")
+		return
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("получен ответ с кодом отличным от 200 от внешнего API: %s", resp.Status)
+	etag := songETag(song)
+	c.Header("ETag", etag)
+	if notModified(c, etag) {
+		c.Status(http.StatusNotModified)
+		return
 	}
 
-	var songDetail storages.SongDetail
-	if err := json.NewDecoder(resp.Body).Decode(&songDetail); err != nil {
-		return nil, fmt.Errorf("не удалось декодировать ответ: %v", err)
+	c.JSON(http.StatusOK, song)
+}
+
+// AddSong
+// @Summary Добавить новую песню
+// @Description Добавить новую песню в базу данных. Дата выхода, ссылка и текст заполняются
+// @Description фоновым обогащением, пока оно не завершится песня имеет статус enrichment_status=pending
+// @Tags Песни
+// @Param song body storages.Song true "Данные о песне"
+// @Success 201 {object} storages.Song
+// @Failure 400 {object} problem.Problem "Неверные данные для добавления песни"
+// @Failure 422 {object} problem.Problem "Не заданы группа или название песни"
+// @Failure 500 {object} problem.Problem "Не удалось добавить песню"
+// @Failure 504 {object} problem.Problem "Превышено время ожидания ответа базы данных"
+// @Failure 401 {object} problem.Problem "Требуется аутентификация"
+// @Failure 403 {object} problem.Problem "Недостаточно прав"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /song [post]
+func (h *Handler) AddSong(c *gin.Context) {
+	var song storages.Song
+	if !bindJSON(c, &song) {
+		return
+	}
+	if err := (&storages.SongPatch{Group: &song.Group, Name: &song.Name}).Normalize(); err != nil {
+		h.respondValidationError(c, err)
+		return
+	}
+
+	h.log(c).Infof("Добавление новой песни: %+v", song)
+
+	// Детали песни запрашиваются воркером обогащения, поэтому клиент не ждёт внешний API
+	song.ReleaseDate = ""
+	song.Link = ""
+	song.EnrichmentStatus = storages.EnrichmentPending
+
+	id, err := h.storage.AddSong(c.Request.Context(), actor(c), song, nil)
+	if err != nil {
+		h.respondError(c, err, "", "Не удалось добавить песню")
+		return
+	}
+
+	created, err := h.storage.GetSong(c.Request.Context(), id)
+	if err != nil {
+		h.respondError(c, err, "", "Не удалось получить добавленную песню")
+		return
 	}
 
-	return &songDetail, nil
+	h.log(c).Infof("Песня успешно добавлена и поставлена в очередь обогащения: %+v", created)
+	c.Header("Location", fmt.Sprintf("/api/v1/song/%d", id))
+	c.JSON(http.StatusCreated, created)
 }
@@ -0,0 +1,119 @@
+package hanlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"songs/internal/storages"
+)
+
+// GetTrash
+// @Summary Получить содержимое корзины
+// @Description Получить список удалённых песен. Поддерживает те же фильтры, сортировку и пагинацию, что и список песен.
+// @Description По умолчанию сначала идут недавно удалённые песни
+// @Tags Корзина
+// @Param group query string false "Фильтр по названию группы"
+// @Param song query string false "Фильтр по названию песни"
+// @Param sort query string false "Поля сортировки через запятую, минус означает убывание" default(-deleted_at)
+// @Param page query int false "Номер страницы" default(1)
+// @Param limit query int false "Количество элементов на странице" default(10)
+// @Param cursor query string false "Курсор next или prev из предыдущего ответа, при наличии курсора page игнорируется"
+// @Success 200 {object} storages.SongsPage
+// @Failure 400 {object} problem.Problem "Неверные параметры запроса"
+// @Failure 500 {object} problem.Problem "Не удалось получить корзину"
+// @Failure 504 {object} problem.Problem "Превышено время ожидания ответа базы данных"
+// @Failure 401 {object} problem.Problem "Требуется аутентификация"
+// @Failure 403 {object} problem.Problem "Недостаточно прав"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /trash [get]
+func (h *Handler) GetTrash(c *gin.Context) {
+	p := newParams(c)
+	page, limit := p.page(10)
+	cursor := c.Query("cursor")
+	filter := p.songFilter()
+	if !p.valid() {
+		return
+	}
+	filter.Trashed = true
+	if len(filter.Sort) == 0 {
+		filter.Sort = []storages.SortField{{Field: "deleted_at", Desc: true}}
+	}
+
+	h.log(c).Infof("Получение корзины с filter=%+v, page=%d, limit=%d, cursor=%s", filter, page, limit, cursor)
+
+	songs, err := h.storage.GetSongs(c.Request.Context(), filter, storages.Pagination{Page: page, Limit: limit, Cursor: cursor})
+	if err != nil {
+		h.respondError(c, err, "", "Не удалось получить корзину")
+		return
+	}
+
+	c.JSON(http.StatusOK, songs)
+}
+
+// RestoreSong
+// @Summary Восстановить песню из корзины
+// @Description Вернуть удалённую песню в список активных вместе с текстом
+// @Tags Корзина
+// @Param id path int true "ID песни"
+// @Success 200 {object} storages.Song
+// @Header 200 {string} ETag "Версия песни"
+// @Failure 404 {object} problem.Problem "Песня не найдена в корзине"
+// @Failure 500 {object} problem.Problem "Не удалось восстановить песню"
+// @Failure 504 {object} problem.Problem "Превышено время ожидания ответа базы данных"
+// @Failure 401 {object} problem.Problem "Требуется аутентификация"
+// @Failure 403 {object} problem.Problem "Недостаточно прав"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /trash/{id}/restore [post]
+func (h *Handler) RestoreSong(c *gin.Context) {
+	p := newParams(c)
+	id := p.id("id")
+	if !p.valid() {
+		return
+	}
+
+	h.log(c).Infof("Восстановление песни с ID=%d из корзины", id)
+
+	song, err := h.storage.RestoreSong(c.Request.Context(), actor(c), id)
+	if err != nil {
+		h.respondError(c, err, "Песня не найдена в корзине", "Не удалось восстановить песню")
+		return
+	}
+
+	h.log(c).Infof("Песня с ID=%d восстановлена из корзины", id)
+	c.Header("ETag", songETag(song))
+	c.JSON(http.StatusOK, song)
+}
+
+// PurgeSong
+// @Summary Окончательно удалить песню
+// @Description Удалить песню из корзины без возможности восстановления. Текст песни удаляется вместе с ней
+// @Tags Корзина
+// @Param id path int true "ID песни"
+// @Success 204 "Песня удалена окончательно"
+// @Failure 404 {object} problem.Problem "Песня не найдена в корзине"
+// @Failure 500 {object} problem.Problem "Не удалось удалить песню"
+// @Failure 504 {object} problem.Problem "Превышено время ожидания ответа базы данных"
+// @Failure 401 {object} problem.Problem "Требуется аутентификация"
+// @Failure 403 {object} problem.Problem "Недостаточно прав"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /trash/{id} [delete]
+func (h *Handler) PurgeSong(c *gin.Context) {
+	p := newParams(c)
+	id := p.id("id")
+	if !p.valid() {
+		return
+	}
+
+	h.log(c).Infof("Окончательное удаление песни с ID=%d", id)
+
+	err := h.storage.PurgeSong(c.Request.Context(), actor(c), id)
+	if err != nil {
+		h.respondError(c, err, "Песня не найдена в корзине", "Не удалось удалить песню")
+		return
+	}
+
+	h.log(c).Infof("Песня с ID=%d удалена окончательно", id)
+	c.Status(http.StatusNoContent)
+}
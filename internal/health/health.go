@@ -0,0 +1,154 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Состояния проверок и отчёта о готовности
+const (
+	StatusOK       = "ok"
+	StatusFail     = "fail"
+	StatusDegraded = "degraded"
+	StatusDraining = "draining"
+)
+
+// Причины сбоя проверки в отчёте. Отчёт доступен без аутентификации,
+// поэтому текст ошибки зависимости в него не попадает, а пишется в журнал
+const (
+	ErrorTimeout     = "timeout"
+	ErrorUnavailable = "unavailable"
+)
+
+// Check проверка одной зависимости экземпляра
+type Check struct {
+	// Имя зависимости в отчёте
+	Name string
+	// Сбой обязательной зависимости делает экземпляр неготовым.
+	// Сбой необязательной только помечает отчёт как degraded
+	Critical bool
+	// Проверка, которая должна уложиться во время из ctx
+	Probe func(ctx context.Context) error
+}
+
+// Result результат проверки зависимости
+type Result struct {
+	Status    string  `json:"status" example:"ok"`
+	LatencyMs float64 `json:"latency_ms" example:"1.25"`
+	Error     string  `json:"error,omitempty" example:"unavailable"`
+}
+
+// Report отчёт о готовности экземпляра по зависимостям
+type Report struct {
+	Status string            `json:"status" example:"ok"`
+	Checks map[string]Result `json:"checks,omitempty"`
+}
+
+// Checker отвечает на проверки живости и готовности экземпляра.
+// После Drain экземпляр сообщает о неготовности, чтобы балансировщик перестал направлять ему запросы
+type Checker struct {
+	checks   []Check
+	timeout  time.Duration
+	logger   *logrus.Logger
+	draining atomic.Bool
+}
+
+// New создаёт Checker с проверками checks, каждая из которых ограничена временем timeout.
+// Ошибки проверок пишутся в logger
+func New(timeout time.Duration, logger *logrus.Logger, checks ...Check) *Checker {
+	return &Checker{checks: checks, timeout: timeout, logger: logger}
+}
+
+// Drain переводит экземпляр в состояние остановки: дальнейшие проверки готовности завершаются отказом
+func (h *Checker) Drain() {
+	h.draining.Store(true)
+}
+
+// Ready выполняет проверки параллельно и собирает отчёт
+func (h *Checker) Ready(ctx context.Context) Report {
+	if h.draining.Load() {
+		return Report{Status: StatusDraining}
+	}
+
+	report := Report{Status: StatusOK, Checks: make(map[string]Result, len(h.checks))}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, check := range h.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res := h.run(ctx, check)
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[check.Name] = res
+			if res.Status == StatusOK {
+				return
+			}
+			if check.Critical {
+				report.Status = StatusFail
+			} else if report.Status == StatusOK {
+				report.Status = StatusDegraded
+			}
+		}()
+	}
+	wg.Wait()
+	return report
+}
+
+func (h *Checker) run(ctx context.Context, check Check) Result {
+	if h.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	err := check.Probe(ctx)
+	res := Result{Status: StatusOK, LatencyMs: float64(time.Since(start).Microseconds()) / 1000}
+	if err != nil {
+		res.Status = StatusFail
+		res.Error = ErrorUnavailable
+		if errors.Is(err, context.DeadlineExceeded) || errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			res.Error = ErrorTimeout
+		}
+		h.logger.WithContext(ctx).Warnf("Проверка готовности %s не пройдена: %v", check.Name, err)
+	}
+	return res
+}
+
+// Liveness godoc
+// @Summary Проверка живости
+// @Description Отвечает 200, пока процесс обслуживает HTTP-запросы. Зависимости не проверяются
+// @Tags Состояние
+// @Produce json
+// @Success 200 {object} Report
+// @Router /healthz [get]
+func (h *Checker) Liveness(c *gin.Context) {
+	c.JSON(http.StatusOK, Report{Status: StatusOK})
+}
+
+// Readiness godoc
+// @Summary Проверка готовности
+// @Description Проверяет доступность Postgres, версию схемы базы и, если включено, внешний API.
+// @Description Возвращает 503, если обязательная зависимость недоступна или экземпляр останавливается.
+// @Description Сбой необязательной зависимости отмечается статусом degraded с кодом 200
+// @Tags Состояние
+// @Produce json
+// @Success 200 {object} Report
+// @Failure 503 {object} Report
+// @Router /readyz [get]
+func (h *Checker) Readiness(c *gin.Context) {
+	report := h.Ready(c.Request.Context())
+	status := http.StatusOK
+	if report.Status == StatusFail || report.Status == StatusDraining {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, report)
+}
@@ -0,0 +1,90 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReady(t *testing.T) {
+	secret := errors.New("dial tcp 10.0.0.5:5432: password authentication failed for user songs")
+	ok := func(context.Context) error { return nil }
+	fail := func(context.Context) error { return secret }
+	hang := func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	tests := []struct {
+		name       string
+		checks     []Check
+		wantStatus string
+		wantErrors map[string]string
+	}{
+		{
+			name:       "все зависимости доступны",
+			checks:     []Check{{Name: "postgres", Critical: true, Probe: ok}, {Name: "external_api", Probe: ok}},
+			wantStatus: StatusOK,
+			wantErrors: map[string]string{"postgres": "", "external_api": ""},
+		},
+		{
+			name:       "сбой обязательной зависимости",
+			checks:     []Check{{Name: "postgres", Critical: true, Probe: fail}, {Name: "external_api", Probe: ok}},
+			wantStatus: StatusFail,
+			wantErrors: map[string]string{"postgres": ErrorUnavailable, "external_api": ""},
+		},
+		{
+			name:       "сбой необязательной зависимости",
+			checks:     []Check{{Name: "postgres", Critical: true, Probe: ok}, {Name: "external_api", Probe: fail}},
+			wantStatus: StatusDegraded,
+			wantErrors: map[string]string{"postgres": "", "external_api": ErrorUnavailable},
+		},
+		{
+			name:       "проверка не уложилась во время",
+			checks:     []Check{{Name: "postgres", Critical: true, Probe: hang}},
+			wantStatus: StatusFail,
+			wantErrors: map[string]string{"postgres": ErrorTimeout},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger, hook := test.NewNullLogger()
+			report := New(10*time.Millisecond, logger, tt.checks...).Ready(context.Background())
+
+			if report.Status != tt.wantStatus {
+				t.Fatalf("статус %s, ожидается %s", report.Status, tt.wantStatus)
+			}
+			for name, want := range tt.wantErrors {
+				if got := report.Checks[name].Error; got != want {
+					t.Fatalf("ошибка проверки %s: %q, ожидается %q", name, got, want)
+				}
+			}
+			// Подробности сбоя остаются в журнале сервера
+			for _, entry := range hook.AllEntries() {
+				if entry.Level != logrus.WarnLevel {
+					t.Fatalf("уровень записи %s, ожидается warning", entry.Level)
+				}
+			}
+			if tt.wantStatus != StatusOK && len(hook.AllEntries()) == 0 {
+				t.Fatal("сбой проверки не записан в журнал")
+			}
+			if tt.wantErrors["postgres"] == ErrorUnavailable && !strings.Contains(hook.LastEntry().Message, secret.Error()) {
+				t.Fatalf("в журнале нет текста ошибки: %q", hook.LastEntry().Message)
+			}
+		})
+	}
+}
+
+func TestReadyDraining(t *testing.T) {
+	logger, _ := test.NewNullLogger()
+	checker := New(time.Second, logger, Check{Name: "postgres", Critical: true, Probe: func(context.Context) error { return nil }})
+	checker.Drain()
+	if report := checker.Ready(context.Background()); report.Status != StatusDraining || report.Checks != nil {
+		t.Fatalf("отчёт %+v, ожидается статус %s без проверок", report, StatusDraining)
+	}
+}
@@ -0,0 +1,106 @@
+package metrics
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"net/http"
+	"songs/internal/storages"
+	"strconv"
+	"time"
+)
+
+const namespace = "songs"
+
+// Metrics реестр метрик приложения в формате Prometheus.
+// Метрики HTTP собирает Middleware, метрики хранилища и внешнего API — декораторы Storage и SongInfo
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httpDuration     *prometheus.HistogramVec
+	httpRequests     *prometheus.CounterVec
+	storageDuration  *prometheus.HistogramVec
+	songInfoDuration *prometheus.HistogramVec
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Время обработки HTTP-запроса по маршрутам.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route"}),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "Количество обработанных HTTP-запросов по маршрутам и кодам ответа.",
+		}, []string{"method", "route", "status"}),
+		storageDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "storage_operation_duration_seconds",
+			Help:      "Время выполнения операций хранилища по методам и результатам.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "result"}),
+		songInfoDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "songinfo_request_duration_seconds",
+			Help:      "Время получения информации о песне из внешних источников по результатам.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"result"}),
+	}
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpDuration, m.httpRequests, m.storageDuration, m.songInfoDuration,
+	)
+	return m
+}
+
+// Handler отдаёт метрики в текстовом формате Prometheus
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// Middleware измеряет время обработки запросов и считает коды ответов.
+// Запросы группируются по шаблону маршрута (/api/v1/song/:id), а не по фактическому пути,
+// чтобы число рядов не зависело от ID в запросах
+func (m *Metrics) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		m.httpDuration.WithLabelValues(c.Request.Method, route).Observe(time.Since(start).Seconds())
+		m.httpRequests.WithLabelValues(c.Request.Method, route, strconv.Itoa(c.Writer.Status())).Inc()
+	}
+}
+
+// RegisterDB добавляет показатели пула соединений из sql.DB.Stats()
+func (m *Metrics) RegisterDB(db *sql.DB, name string) error {
+	return m.registry.Register(collectors.NewDBStatsCollector(db, name))
+}
+
+// result относит ошибку хранилища к одной из групп для метки result
+func result(err error) string {
+	switch {
+	case err == nil:
+		return "ok"
+	case errors.Is(err, storages.ErrNotFound):
+		return "not_found"
+	case errors.Is(err, storages.ErrTimeout):
+		return "timeout"
+	case storages.IsRejection(err):
+		// Отказ по данным запроса, а не сбой базы
+		return "rejected"
+	default:
+		return "error"
+	}
+}
@@ -0,0 +1,94 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"songs/internal/songinfo"
+	"songs/internal/storages"
+	"time"
+)
+
+// songInfoProvider декоратор источника информации о песнях, измеряющий время и результат запросов
+type songInfoProvider struct {
+	next    songinfo.SongInfoProvider
+	metrics *Metrics
+}
+
+// SongInfo оборачивает источник информации о песнях сбором метрик
+func (m *Metrics) SongInfo(next songinfo.SongInfoProvider) songinfo.SongInfoProvider {
+	return &songInfoProvider{next: next, metrics: m}
+}
+
+func (p *songInfoProvider) GetSongInfo(ctx context.Context, group string, song string) (*storages.SongDetail, error) {
+	start := time.Now()
+	detail, err := p.next.GetSongInfo(ctx, group, song)
+
+	outcome := "success"
+	switch {
+	case err == nil:
+	case errors.Is(err, songinfo.ErrNotFound):
+		outcome = "not_found"
+	case ctx.Err() != nil:
+		outcome = "canceled"
+	default:
+		outcome = "failure"
+	}
+	p.metrics.songInfoDuration.WithLabelValues(outcome).Observe(time.Since(start).Seconds())
+	return detail, err
+}
+
+// RegisterExternalAPI публикует счётчики клиента внешнего API: попытки, повторы, кэш и выключатель
+func (m *Metrics) RegisterExternalAPI(provider *songinfo.HTTPProvider) error {
+	return m.registry.Register(&externalAPICollector{provider: provider})
+}
+
+var (
+	externalAPIRequests = prometheus.NewDesc(namespace+"_external_api_requests_total",
+		"Запросы к внешнему API, не обслуженные из кэша, по результатам.", []string{"outcome"}, nil)
+	externalAPIAttempts = prometheus.NewDesc(namespace+"_external_api_attempts_total",
+		"Попытки обращения к внешнему API, включая повторы.", nil, nil)
+	externalAPIRetries = prometheus.NewDesc(namespace+"_external_api_retries_total",
+		"Повторные попытки обращения к внешнему API.", nil, nil)
+	externalAPICache = prometheus.NewDesc(namespace+"_external_api_cache_total",
+		"Обращения к кэшу ответов внешнего API по результатам.", []string{"result"}, nil)
+	externalAPIRejects = prometheus.NewDesc(namespace+"_external_api_breaker_rejects_total",
+		"Попытки, отклонённые разомкнутым выключателем без обращения к внешнему API.", nil, nil)
+	externalAPIBreaker = prometheus.NewDesc(namespace+"_external_api_breaker_open",
+		"Состояние автоматического выключателя внешнего API: 1 — разомкнут или пропускает пробный запрос.", nil, nil)
+)
+
+// externalAPICollector переводит снимок HTTPProvider.Stats() в метрики при каждом опросе
+type externalAPICollector struct {
+	provider *songinfo.HTTPProvider
+}
+
+func (c *externalAPICollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- externalAPIRequests
+	ch <- externalAPIAttempts
+	ch <- externalAPIRetries
+	ch <- externalAPICache
+	ch <- externalAPIRejects
+	ch <- externalAPIBreaker
+}
+
+func (c *externalAPICollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.provider.Stats()
+	counter := func(desc *prometheus.Desc, value uint64, labels ...string) {
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.CounterValue, float64(value), labels...)
+	}
+	counter(externalAPIRequests, stats.Successes, "success")
+	counter(externalAPIRequests, stats.NotFound, "not_found")
+	counter(externalAPIRequests, stats.Failures, "failure")
+	counter(externalAPIAttempts, stats.Attempts)
+	counter(externalAPIRetries, stats.Retries)
+	counter(externalAPICache, stats.CacheHits, "hit")
+	counter(externalAPICache, stats.CacheMisses, "miss")
+	counter(externalAPIRejects, stats.BreakerRejects)
+
+	open := 0.0
+	if stats.BreakerState != "closed" {
+		open = 1
+	}
+	ch <- prometheus.MustNewConstMetric(externalAPIBreaker, prometheus.GaugeValue, open)
+}
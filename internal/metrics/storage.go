@@ -0,0 +1,220 @@
+package metrics
+
+import (
+	"context"
+	"songs/internal/storages"
+	"time"
+)
+
+// Storage декоратор хранилища, который измеряет время каждого вызова по имени метода и результату
+type Storage struct {
+	next    storages.Backend
+	metrics *Metrics
+}
+
+var _ storages.Backend = (*Storage)(nil)
+
+// Storage оборачивает хранилище next сбором метрик
+func (m *Metrics) Storage(next storages.Backend) *Storage {
+	return &Storage{next: next, metrics: m}
+}
+
+func (s *Storage) observe(method string, start time.Time, err error) {
+	s.metrics.storageDuration.WithLabelValues(method, result(err)).Observe(time.Since(start).Seconds())
+}
+
+func (s *Storage) GetSongs(ctx context.Context, filter storages.SongFilter, p storages.Pagination) (storages.SongsPage, error) {
+	start := time.Now()
+	res, err := s.next.GetSongs(ctx, filter, p)
+	s.observe("GetSongs", start, err)
+	return res, err
+}
+
+func (s *Storage) GetLyrics(ctx context.Context, songID int, p storages.Pagination) (storages.VersesPage, error) {
+	start := time.Now()
+	res, err := s.next.GetLyrics(ctx, songID, p)
+	s.observe("GetLyrics", start, err)
+	return res, err
+}
+
+func (s *Storage) DeleteSong(ctx context.Context, actor string, id int, version int) error {
+	start := time.Now()
+	err := s.next.DeleteSong(ctx, actor, id, version)
+	s.observe("DeleteSong", start, err)
+	return err
+}
+
+func (s *Storage) RestoreSong(ctx context.Context, actor string, id int) (storages.Song, error) {
+	start := time.Now()
+	res, err := s.next.RestoreSong(ctx, actor, id)
+	s.observe("RestoreSong", start, err)
+	return res, err
+}
+
+func (s *Storage) PurgeSong(ctx context.Context, actor string, id int) error {
+	start := time.Now()
+	err := s.next.PurgeSong(ctx, actor, id)
+	s.observe("PurgeSong", start, err)
+	return err
+}
+
+func (s *Storage) UpdateSong(ctx context.Context, actor string, id int, song storages.Song, version int) (storages.Song, error) {
+	start := time.Now()
+	res, err := s.next.UpdateSong(ctx, actor, id, song, version)
+	s.observe("UpdateSong", start, err)
+	return res, err
+}
+
+func (s *Storage) GetSong(ctx context.Context, id int) (storages.Song, error) {
+	start := time.Now()
+	res, err := s.next.GetSong(ctx, id)
+	s.observe("GetSong", start, err)
+	return res, err
+}
+
+func (s *Storage) AddSong(ctx context.Context, actor string, song storages.Song, verses []storages.Verse) (int, error) {
+	start := time.Now()
+	res, err := s.next.AddSong(ctx, actor, song, verses)
+	s.observe("AddSong", start, err)
+	return res, err
+}
+
+func (s *Storage) AddLyrics(ctx context.Context, actor string, songID int, lines []string) (storages.Verse, error) {
+	start := time.Now()
+	res, err := s.next.AddLyrics(ctx, actor, songID, lines)
+	s.observe("AddLyrics", start, err)
+	return res, err
+}
+
+func (s *Storage) UpdateSongPartial(ctx context.Context, actor string, id int, patch storages.SongPatch, version int) (storages.Song, error) {
+	start := time.Now()
+	res, err := s.next.UpdateSongPartial(ctx, actor, id, patch, version)
+	s.observe("UpdateSongPartial", start, err)
+	return res, err
+}
+
+func (s *Storage) SearchLyrics(ctx context.Context, phrase string, language string, page int, limit int) ([]storages.SearchResult, error) {
+	start := time.Now()
+	res, err := s.next.SearchLyrics(ctx, phrase, language, page, limit)
+	s.observe("SearchLyrics", start, err)
+	return res, err
+}
+
+func (s *Storage) GetEnrichmentJob(ctx context.Context, songID int) (storages.EnrichmentJob, error) {
+	start := time.Now()
+	res, err := s.next.GetEnrichmentJob(ctx, songID)
+	s.observe("GetEnrichmentJob", start, err)
+	return res, err
+}
+
+func (s *Storage) RetryEnrichment(ctx context.Context, actor string, songID int) (storages.EnrichmentJob, error) {
+	start := time.Now()
+	res, err := s.next.RetryEnrichment(ctx, actor, songID)
+	s.observe("RetryEnrichment", start, err)
+	return res, err
+}
+
+func (s *Storage) GetSongHistory(ctx context.Context, songID int, page int, limit int) ([]storages.AuditEntry, error) {
+	start := time.Now()
+	res, err := s.next.GetSongHistory(ctx, songID, page, limit)
+	s.observe("GetSongHistory", start, err)
+	return res, err
+}
+
+func (s *Storage) RevertSong(ctx context.Context, actor string, id int, revision int64, version int) (storages.Song, error) {
+	start := time.Now()
+	res, err := s.next.RevertSong(ctx, actor, id, revision, version)
+	s.observe("RevertSong", start, err)
+	return res, err
+}
+
+func (s *Storage) GetGroups(ctx context.Context, name string, page int, limit int) ([]storages.Group, error) {
+	start := time.Now()
+	res, err := s.next.GetGroups(ctx, name, page, limit)
+	s.observe("GetGroups", start, err)
+	return res, err
+}
+
+func (s *Storage) GetGroup(ctx context.Context, id int) (storages.Group, error) {
+	start := time.Now()
+	res, err := s.next.GetGroup(ctx, id)
+	s.observe("GetGroup", start, err)
+	return res, err
+}
+
+func (s *Storage) AddGroup(ctx context.Context, group storages.Group) (int, error) {
+	start := time.Now()
+	res, err := s.next.AddGroup(ctx, group)
+	s.observe("AddGroup", start, err)
+	return res, err
+}
+
+func (s *Storage) UpdateGroup(ctx context.Context, actor string, id int, group storages.Group) error {
+	start := time.Now()
+	err := s.next.UpdateGroup(ctx, actor, id, group)
+	s.observe("UpdateGroup", start, err)
+	return err
+}
+
+func (s *Storage) DeleteGroup(ctx context.Context, id int) error {
+	start := time.Now()
+	err := s.next.DeleteGroup(ctx, id)
+	s.observe("DeleteGroup", start, err)
+	return err
+}
+
+func (s *Storage) ClaimEnrichmentJob(ctx context.Context, lease time.Duration) (storages.EnrichmentJob, error) {
+	start := time.Now()
+	res, err := s.next.ClaimEnrichmentJob(ctx, lease)
+	s.observe("ClaimEnrichmentJob", start, err)
+	return res, err
+}
+
+func (s *Storage) CompleteEnrichmentJob(ctx context.Context, job storages.EnrichmentJob, detail storages.SongDetail) error {
+	start := time.Now()
+	err := s.next.CompleteEnrichmentJob(ctx, job, detail)
+	s.observe("CompleteEnrichmentJob", start, err)
+	return err
+}
+
+func (s *Storage) RescheduleEnrichmentJob(ctx context.Context, job storages.EnrichmentJob, reason string, runAt time.Time) error {
+	start := time.Now()
+	err := s.next.RescheduleEnrichmentJob(ctx, job, reason, runAt)
+	s.observe("RescheduleEnrichmentJob", start, err)
+	return err
+}
+
+func (s *Storage) DeadLetterEnrichmentJob(ctx context.Context, job storages.EnrichmentJob, reason string) error {
+	start := time.Now()
+	err := s.next.DeadLetterEnrichmentJob(ctx, job, reason)
+	s.observe("DeadLetterEnrichmentJob", start, err)
+	return err
+}
+
+func (s *Storage) CreateAPIKey(ctx context.Context, key storages.APIKey, hash string) (storages.APIKey, error) {
+	start := time.Now()
+	res, err := s.next.CreateAPIKey(ctx, key, hash)
+	s.observe("CreateAPIKey", start, err)
+	return res, err
+}
+
+func (s *Storage) GetAPIKeys(ctx context.Context, page int, limit int) ([]storages.APIKey, error) {
+	start := time.Now()
+	res, err := s.next.GetAPIKeys(ctx, page, limit)
+	s.observe("GetAPIKeys", start, err)
+	return res, err
+}
+
+func (s *Storage) RevokeAPIKey(ctx context.Context, id int) error {
+	start := time.Now()
+	err := s.next.RevokeAPIKey(ctx, id)
+	s.observe("RevokeAPIKey", start, err)
+	return err
+}
+
+func (s *Storage) AuthenticateAPIKey(ctx context.Context, hash string) (storages.APIKey, error) {
+	start := time.Now()
+	res, err := s.next.AuthenticateAPIKey(ctx, hash)
+	s.observe("AuthenticateAPIKey", start, err)
+	return res, err
+}
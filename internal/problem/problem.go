@@ -0,0 +1,51 @@
+// Package problem формирует ответы об ошибках в формате RFC 7807 (application/problem+json)
+package problem
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// ContentType тип содержимого ответа об ошибке
+const ContentType = "application/problem+json"
+
+// Problem описание ошибки запроса. Type всегда about:blank, поэтому Title совпадает
+// с текстом HTTP-статуса, а подробности для человека передаются в Detail
+type Problem struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	// Ошибки отдельных параметров запроса
+	Errors []FieldError `json:"errors,omitempty"`
+}
+
+// Расположение параметра с ошибкой
+const (
+	InPath   = "path"
+	InQuery  = "query"
+	InHeader = "header"
+	InBody   = "body"
+)
+
+// FieldError ошибка значения одного параметра запроса
+type FieldError struct {
+	In      string `json:"in"`
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Respond прерывает обработку запроса и отвечает описанием ошибки.
+// detail не должен содержать внутренних подробностей, например текста ошибок базы данных
+func Respond(c *gin.Context, status int, detail string, errs ...FieldError) {
+	c.Header("Content-Type", ContentType)
+	c.AbortWithStatusJSON(status, Problem{
+		Type:     "about:blank",
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   detail,
+		Instance: c.Request.URL.Path,
+		Errors:   errs,
+	})
+}
@@ -0,0 +1,47 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"math"
+	"net/http"
+	"songs/internal/auth"
+	"songs/internal/problem"
+	"songs/pkg/ratelimit"
+	"strconv"
+	"time"
+)
+
+// rateLimit ограничивает частоту запросов клиента к группе маршрутов group.
+// Клиент определяется по ключу API или пользователю JWT, анонимный — по IP-адресу.
+// При недоступности хранилища корзин запрос пропускается, чтобы сбой лимитера не останавливал API
+func rateLimit(store ratelimit.Store, group string, limit ratelimit.Limit, logger *logrus.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		client := "ip:" + c.ClientIP()
+		if principal, ok := auth.PrincipalFrom(c); ok {
+			client = "sub:" + principal.Subject
+		}
+
+		result, err := store.Take(group+"|"+client, limit)
+		if err != nil {
+			logger.WithContext(c.Request.Context()).Errorf("Не удалось проверить ограничение частоты запросов: %v", err)
+			c.Next()
+			return
+		}
+
+		c.Header("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Header("X-RateLimit-Reset", ceilSeconds(result.Reset))
+		if !result.Allowed {
+			c.Header("Retry-After", ceilSeconds(result.RetryAfter))
+			problem.Respond(c, http.StatusTooManyRequests, "Слишком много запросов, повторите позже")
+			return
+		}
+		c.Next()
+	}
+}
+
+// ceilSeconds округляет длительность вверх до целых секунд, как требуют заголовки
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,89 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"songs/internal/config"
+	"songs/pkg/ratelimit"
+	"testing"
+)
+
+// newLimitedRouter собирает маршрутизатор, в котором аутентификация отклоняет все запросы,
+// как при переборе ключей API, а лимит до аутентификации пропускает один запрос с адреса
+func newLimitedRouter(t *testing.T, trustedProxies []string) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	cfg := &config.Config{}
+	cfg.Server.TrustedProxies = trustedProxies
+	cfg.RateLimit.Enabled = true
+	cfg.RateLimit.IPRate = 0.001
+	cfg.RateLimit.IPBurst = 1
+	cfg.Metrics.Path = "/metrics"
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	rejectAll := func(c *gin.Context) { c.AbortWithStatus(http.StatusUnauthorized) }
+	router, err := SetupRouter(nil, rejectAll, ratelimit.NewMemoryStore(), nil, nil, cfg, logger)
+	if err != nil {
+		t.Fatalf("SetupRouter: %v", err)
+	}
+	return router
+}
+
+func request(router *gin.Engine, remoteAddr string, forwardedFor string) int {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/songs", nil)
+	req.RemoteAddr = remoteAddr
+	if forwardedFor != "" {
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		req.Header.Set("X-Real-IP", forwardedFor)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w.Code
+}
+
+func TestIPLimitIgnoresSpoofedForwardedFor(t *testing.T) {
+	router := newLimitedRouter(t, nil)
+
+	if code := request(router, "203.0.113.7:40000", "198.51.100.1"); code != http.StatusUnauthorized {
+		t.Fatalf("первый запрос: код %d, ожидается %d", code, http.StatusUnauthorized)
+	}
+	// Новый X-Forwarded-For не даёт новой корзины: адрес берётся из соединения
+	if code := request(router, "203.0.113.7:40001", "198.51.100.2"); code != http.StatusTooManyRequests {
+		t.Fatalf("запрос с подменённым X-Forwarded-For: код %d, ожидается %d", code, http.StatusTooManyRequests)
+	}
+	// Другой клиент получает свою корзину
+	if code := request(router, "203.0.113.8:40000", ""); code != http.StatusUnauthorized {
+		t.Fatalf("запрос другого клиента: код %d, ожидается %d", code, http.StatusUnauthorized)
+	}
+}
+
+func TestIPLimitUsesForwardedForFromTrustedProxy(t *testing.T) {
+	router := newLimitedRouter(t, []string{"10.0.0.0/8"})
+
+	if code := request(router, "10.0.0.1:40000", "198.51.100.1"); code != http.StatusUnauthorized {
+		t.Fatalf("первый клиент: код %d, ожидается %d", code, http.StatusUnauthorized)
+	}
+	// За доверенным прокси клиенты различаются по X-Forwarded-For
+	if code := request(router, "10.0.0.1:40001", "198.51.100.2"); code != http.StatusUnauthorized {
+		t.Fatalf("второй клиент: код %d, ожидается %d", code, http.StatusUnauthorized)
+	}
+	if code := request(router, "10.0.0.1:40002", "198.51.100.1"); code != http.StatusTooManyRequests {
+		t.Fatalf("повторный запрос первого клиента: код %d, ожидается %d", code, http.StatusTooManyRequests)
+	}
+}
+
+func TestSetupRouterRejectsInvalidTrustedProxies(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Server.TrustedProxies = []string{"not-an-ip"}
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	if _, err := SetupRouter(nil, func(*gin.Context) {}, ratelimit.NewMemoryStore(), nil, nil, cfg, logger); err == nil {
+		t.Fatal("ожидается ошибка для некорректного адреса прокси")
+	}
+}
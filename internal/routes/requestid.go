@@ -0,0 +1,83 @@
+package routes
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"songs/pkg/logger"
+	"time"
+)
+
+// RequestIDHeader заголовок с идентификатором запроса для сопоставления записей журнала
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength ограничивает длину идентификатора, присланного клиентом
+const maxRequestIDLength = 128
+
+// requestID присваивает запросу идентификатор и возвращает его в заголовке ответа.
+// Идентификатор клиента или прокси используется, если он допустим, иначе создаётся новый.
+// Идентификатор кладётся в контекст запроса, поэтому попадает в записи журнала обработчиков и хранилища
+func requestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+		c.Request = c.Request.WithContext(logger.WithRequestID(c.Request.Context(), id))
+		c.Header(RequestIDHeader, id)
+		// Идентификатор в спане позволяет найти трассу по записи журнала
+		trace.SpanFromContext(c.Request.Context()).SetAttributes(attribute.String("http.request_id", id))
+		c.Next()
+	}
+}
+
+// validRequestID допускает непустые идентификаторы из букв, цифр и символов "-_.:",
+// чтобы клиент не мог подставить в журнал произвольный текст
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '-', r == '_', r == '.', r == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// accessLog записывает в журнал каждый обработанный запрос вместе с его идентификатором.
+// Ответы 5xx пишутся с уровнем error, 4xx — warn
+func accessLog(log *logrus.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		entry := log.WithContext(c.Request.Context()).WithFields(logrus.Fields{
+			"method":  c.Request.Method,
+			"path":    c.Request.URL.Path,
+			"status":  c.Writer.Status(),
+			"latency": time.Since(start).String(),
+			"client":  c.ClientIP(),
+		})
+		switch status := c.Writer.Status(); {
+		case status >= 500:
+			entry.Error("Запрос обработан")
+		case status >= 400:
+			entry.Warn("Запрос обработан")
+		default:
+			entry.Info("Запрос обработан")
+		}
+	}
+}
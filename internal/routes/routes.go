@@ -1,30 +1,114 @@
 package routes
 
 import (
+	"fmt"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 	_ "songs/docs"
+	"songs/internal/auth"
+	"songs/internal/config"
 	"songs/internal/hanlers"
+	"songs/internal/health"
+	"songs/internal/metrics"
+	"songs/pkg/ratelimit"
 )
 
-func SetupRouter(songHandler *hanlers.Handler) *gin.Engine {
-	router := gin.Default()
-	router.Use(cors.Default())
+// SetupRouter настраивает маршруты API. authenticate определяет клиента запроса,
+// после чего группы маршрутов ограничивают частоту его запросов по корзинам из limits и проверяют его роль.
+// checker отвечает на проверки живости и готовности по путям /healthz и /readyz.
+// Если передан m, запросы измеряются, а метрики отдаются по пути из конфигурации
+func SetupRouter(songHandler *hanlers.Handler, authenticate gin.HandlerFunc, limits ratelimit.Store, checker *health.Checker, m *metrics.Metrics, cfg *config.Config, logger *logrus.Logger) (*gin.Engine, error) {
+	// Вместо журнала Gin запросы пишутся в общий журнал вместе с идентификатором запроса.
+	// Каждый запрос открывает спан, продолжая трассу из заголовка traceparent, если он передан
+	router := gin.New()
+
+	// Адрес клиента берётся из X-Forwarded-For только от перечисленных прокси, без них — из соединения.
+	// По этому адресу считаются лимиты анонимных клиентов и лимит до аутентификации
+	if err := router.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		return nil, fmt.Errorf("некорректный список доверенных прокси: %w", err)
+	}
+
+	// Проверки состояния регистрируются до общих middleware: оркестратор опрашивает их постоянно,
+	// и они не должны засорять журнал, трассы и метрики запросов
+	router.GET("/healthz", gin.Recovery(), checker.Liveness)
+	router.GET("/readyz", gin.Recovery(), checker.Readiness)
+
+	router.Use(otelgin.Middleware(cfg.Tracing.ServiceName), requestID(), accessLog(logger), gin.Recovery())
+	if m != nil {
+		router.Use(m.Middleware())
+		router.GET(cfg.Metrics.Path, gin.WrapH(m.Handler()))
+	}
+
+	// Браузерным клиентам нужны заголовки авторизации и условных запросов, а также доступ к ETag ответа
+	corsConfig := cors.DefaultConfig()
+	corsConfig.AllowAllOrigins = true
+	corsConfig.AddAllowHeaders("Authorization", auth.APIKeyHeader, "If-Match", "If-None-Match", RequestIDHeader)
+	corsConfig.AddExposeHeaders("ETag", "Location", "WWW-Authenticate", RequestIDHeader,
+		"X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset", "Retry-After")
+	router.Use(cors.New(corsConfig))
 
 	// Swagger документация
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
-	// Публичные маршруты
-	public := router.Group("/api/v1")
+	limit := func(group string, rate float64, burst int) gin.HandlerFunc {
+		if !cfg.RateLimit.Enabled || rate <= 0 {
+			return func(c *gin.Context) { c.Next() }
+		}
+		return rateLimit(limits, group, ratelimit.Limit{Rate: rate, Burst: burst}, logger)
+	}
+
+	// Лимит по IP стоит перед аутентификацией: запросы с неверным токеном или ключом API
+	// отклоняются до неё, поэтому их тоже нужно ограничивать, а клиент на этом шаге ещё не известен
+	api := router.Group("/api/v1", limit("ip", cfg.RateLimit.IPRate, cfg.RateLimit.IPBurst), authenticate)
+
+	// Чтение открыто всем, либо только с ролью reader, если так задано в конфигурации
+	read := api.Group("", limit("read", cfg.RateLimit.ReadRate, cfg.RateLimit.ReadBurst))
+	if !cfg.Auth.PublicRead {
+		read.Use(auth.RequireRole(auth.RoleReader))
+	}
+	{
+		read.GET("/songs", songHandler.GetSongs)
+		read.GET("/song/:id/lyrics", songHandler.GetLyrics)
+		read.GET("/song/:id", songHandler.GetSong)
+		read.GET("/search", songHandler.SearchLyrics)
+		read.GET("/song/:id/enrichment", songHandler.GetEnrichment)
+		read.GET("/song/:id/history", songHandler.GetSongHistory)
+
+		read.GET("/groups", songHandler.GetGroups)
+		read.GET("/groups/:id", songHandler.GetGroup)
+	}
+
+	// Изменение данных доступно редакторам и администраторам
+	write := api.Group("", limit("write", cfg.RateLimit.WriteRate, cfg.RateLimit.WriteBurst), auth.RequireRole(auth.RoleEditor))
+	{
+		write.POST("/song", songHandler.AddSong)
+		write.DELETE("/song/:id", songHandler.DeleteSong)
+		write.PUT("/song/:id", songHandler.UpdateSong)
+		write.PATCH("/song/:id", songHandler.UpdateSongPartial)
+		write.POST("/song/:id/enrichment", songHandler.RetryEnrichment)
+		write.POST("/song/:id/history/:revision/revert", songHandler.RevertSong)
+
+		write.GET("/trash", songHandler.GetTrash)
+		write.POST("/trash/:id/restore", songHandler.RestoreSong)
+
+		write.POST("/groups", songHandler.AddGroup)
+		write.PUT("/groups/:id", songHandler.UpdateGroup)
+	}
+
+	// Безвозвратное удаление только для администраторов
+	admin := api.Group("", limit("admin", cfg.RateLimit.AdminRate, cfg.RateLimit.AdminBurst), auth.RequireRole(auth.RoleAdmin))
 	{
-		public.GET("/songs", songHandler.GetSongs)
-		public.GET("/song/:id/lyrics", songHandler.GetLyrics)
-		public.POST("/song", songHandler.AddSong)
-		public.DELETE("/song/:id", songHandler.DeleteSong)
-		public.PUT("/song/:id", songHandler.UpdateSongPartial)
+		admin.DELETE("/trash/:id", songHandler.PurgeSong)
+		admin.DELETE("/groups/:id", songHandler.DeleteGroup)
+
+		admin.POST("/admin/api-keys", songHandler.CreateAPIKey)
+		admin.GET("/admin/api-keys", songHandler.GetAPIKeys)
+		admin.DELETE("/admin/api-keys/:id", songHandler.RevokeAPIKey)
 	}
 
-	return router
+	return router, nil
 }
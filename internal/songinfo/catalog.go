@@ -0,0 +1,73 @@
+package songinfo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"os"
+	"path/filepath"
+	"songs/internal/storages"
+	"strings"
+)
+
+// CatalogEntry запись локального каталога песен
+type CatalogEntry struct {
+	Group       string `json:"group" yaml:"group"`
+	Song        string `json:"song" yaml:"song"`
+	ReleaseDate string `json:"releaseDate" yaml:"releaseDate"`
+	Text        string `json:"text" yaml:"text"`
+	Link        string `json:"link" yaml:"link"`
+}
+
+// CatalogProvider отдаёт информацию о песнях из локального JSON или YAML файла
+type CatalogProvider struct {
+	songs map[string]storages.SongDetail
+}
+
+// NewCatalogProvider загружает каталог из файла. Формат определяется по расширению:
+// .yaml и .yml разбираются как YAML, всё остальное как JSON.
+func NewCatalogProvider(path string) (*CatalogProvider, error) {
+	if path == "" {
+		return nil, fmt.Errorf("для провайдера catalog необходимо указать SONG_INFO_CATALOG_PATH")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось прочитать каталог песен: %v", err)
+	}
+
+	var entries []CatalogEntry
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &entries)
+	default:
+		err = json.Unmarshal(data, &entries)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("не удалось разобрать каталог песен %s: %v", path, err)
+	}
+
+	return NewCatalogProviderFromEntries(entries), nil
+}
+
+// NewCatalogProviderFromEntries создаёт каталог из уже загруженных записей
+func NewCatalogProviderFromEntries(entries []CatalogEntry) *CatalogProvider {
+	p := &CatalogProvider{songs: make(map[string]storages.SongDetail, len(entries))}
+	for _, e := range entries {
+		p.songs[key(e.Group, e.Song)] = storages.SongDetail{
+			ReleaseDate: e.ReleaseDate,
+			Text:        e.Text,
+			Link:        e.Link,
+		}
+	}
+	return p
+}
+
+func (p *CatalogProvider) GetSongInfo(ctx context.Context, group string, song string) (*storages.SongDetail, error) {
+	detail, ok := p.songs[key(group, song)]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &detail, nil
+}
@@ -0,0 +1,232 @@
+package songinfo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"net"
+	"net/http"
+	"net/url"
+	"songs/internal/storages"
+	"songs/internal/tracing"
+	"songs/pkg/cache"
+	"songs/pkg/resilience"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// HTTPOptions параметры устойчивости клиента внешнего API
+type HTTPOptions struct {
+	// Таймаут одной попытки запроса целиком
+	Timeout time.Duration
+	// Таймаут установки TCP-соединения
+	ConnectTimeout time.Duration
+	// Политика повторов при ответах 5xx и сетевых ошибках
+	Retry resilience.Backoff
+	// Количество неудач подряд, после которого выключатель размыкается
+	BreakerThreshold int
+	// Пауза, после которой разомкнутый выключатель пропускает пробный запрос
+	BreakerCooldown time.Duration
+	// Время жизни и максимальный размер кэша ответов
+	CacheTTL  time.Duration
+	CacheSize int
+	// Транспорт вместо стандартного, например транспорт клиента httptest.Server.
+	// Таймаут попытки Timeout действует и с ним, ConnectTimeout — нет
+	Transport http.RoundTripper
+}
+
+// Stats счётчики обращений к внешнему API
+type Stats struct {
+	Requests       uint64 `json:"requests"`
+	CacheHits      uint64 `json:"cache_hits"`
+	CacheMisses    uint64 `json:"cache_misses"`
+	Attempts       uint64 `json:"attempts"`
+	Retries        uint64 `json:"retries"`
+	Successes      uint64 `json:"successes"`
+	NotFound       uint64 `json:"not_found"`
+	Failures       uint64 `json:"failures"`
+	BreakerRejects uint64 `json:"breaker_rejects"`
+	BreakerState   string `json:"breaker_state"`
+}
+
+type httpStats struct {
+	requests, cacheHits, cacheMisses, attempts, retries atomic.Uint64
+	successes, notFound, failures, breakerRejects       atomic.Uint64
+}
+
+// HTTPProvider получает информацию о песне из внешнего API по контракту GET /info?group=...&song=...
+// Запросы выполняются с таймаутами, повторами, автоматическим выключателем и кэшированием ответов.
+type HTTPProvider struct {
+	baseURL string
+	client  *http.Client
+	retry   resilience.Backoff
+	breaker *resilience.CircuitBreaker
+	cache   *cache.TTL[string, storages.SongDetail]
+	stats   httpStats
+}
+
+func NewHTTPProvider(address string, opts HTTPOptions) *HTTPProvider {
+	transport := opts.Transport
+	if transport == nil {
+		transport = &http.Transport{
+			Proxy:                 http.ProxyFromEnvironment,
+			DialContext:           (&net.Dialer{Timeout: opts.ConnectTimeout}).DialContext,
+			TLSHandshakeTimeout:   opts.ConnectTimeout,
+			ResponseHeaderTimeout: opts.Timeout,
+			MaxIdleConnsPerHost:   10,
+			IdleConnTimeout:       90 * time.Second,
+		}
+	}
+	// Каждая попытка запроса получает клиентский спан, а контекст трассы
+	// передаётся внешнему API в заголовке traceparent
+	client := &http.Client{
+		Timeout:   opts.Timeout,
+		Transport: otelhttp.NewTransport(transport),
+	}
+
+	return &HTTPProvider{
+		baseURL: strings.TrimRight(address, "/"),
+		client:  client,
+		retry:   opts.Retry,
+		breaker: resilience.NewCircuitBreaker(opts.BreakerThreshold, opts.BreakerCooldown),
+		cache:   cache.NewTTL[string, storages.SongDetail](opts.CacheSize, opts.CacheTTL),
+	}
+}
+
+func (p *HTTPProvider) GetSongInfo(ctx context.Context, group string, song string) (detail *storages.SongDetail, err error) {
+	ctx, span := otel.Tracer(tracing.Name).Start(ctx, "songinfo.http", trace.WithAttributes(
+		attribute.String("song.group", group),
+		attribute.String("song.name", song),
+	))
+	defer func() {
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+	p.stats.requests.Add(1)
+
+	k := key(group, song)
+	if cached, ok := p.cache.Get(k); ok {
+		p.stats.cacheHits.Add(1)
+		span.SetAttributes(attribute.Bool("songinfo.cache_hit", true))
+		return &cached, nil
+	}
+	p.stats.cacheMisses.Add(1)
+	span.SetAttributes(attribute.Bool("songinfo.cache_hit", false))
+
+	err = resilience.Retry(ctx, p.retry, func(attempt int) error {
+		if attempt > 1 {
+			p.stats.retries.Add(1)
+		}
+		if err := p.breaker.Allow(); err != nil {
+			p.stats.breakerRejects.Add(1)
+			return resilience.Permanent(err)
+		}
+
+		p.stats.attempts.Add(1)
+		var err error
+		detail, err = p.fetch(ctx, group, song)
+		switch {
+		case err == nil || errors.Is(err, ErrNotFound):
+			// Ответ 404 означает, что сервис работает, поэтому выключатель не размыкается
+			p.breaker.Success()
+		case ctx.Err() != nil:
+			// Отмена запроса клиентом ничего не говорит о состоянии внешнего сервиса
+			p.breaker.Release()
+			return resilience.Permanent(ctx.Err())
+		default:
+			p.breaker.Failure()
+		}
+		return err
+	})
+
+	switch {
+	case err == nil:
+		p.stats.successes.Add(1)
+		p.cache.Set(k, *detail)
+		return detail, nil
+	case errors.Is(err, ErrNotFound):
+		p.stats.notFound.Add(1)
+	default:
+		p.stats.failures.Add(1)
+	}
+	return nil, err
+}
+
+// fetch выполняет одну попытку запроса. Ошибки, которые не имеет смысла повторять,
+// помечаются как постоянные.
+func (p *HTTPProvider) fetch(ctx context.Context, group string, song string) (*storages.SongDetail, error) {
+	params := url.Values{}
+	params.Set("group", group)
+	params.Set("song", song)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.baseURL+"/info?"+params.Encode(), nil)
+	if err != nil {
+		return nil, resilience.Permanent(err)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, resilience.Permanent(ErrNotFound)
+	case resp.StatusCode >= http.StatusInternalServerError:
+		return nil, fmt.Errorf("внешний API вернул ошибку: %s", resp.Status)
+	case resp.StatusCode != http.StatusOK:
+		return nil, resilience.Permanent(fmt.Errorf("получен ответ с кодом отличным от 200 от внешнего API: %s", resp.Status))
+	}
+
+	var songDetail storages.SongDetail
+	if err := json.NewDecoder(resp.Body).Decode(&songDetail); err != nil {
+		return nil, resilience.Permanent(fmt.Errorf("не удалось декодировать ответ: %v", err))
+	}
+
+	return &songDetail, nil
+}
+
+// Stats возвращает снимок счётчиков обращений к внешнему API
+func (p *HTTPProvider) Stats() Stats {
+	return Stats{
+		Requests:       p.stats.requests.Load(),
+		CacheHits:      p.stats.cacheHits.Load(),
+		CacheMisses:    p.stats.cacheMisses.Load(),
+		Attempts:       p.stats.attempts.Load(),
+		Retries:        p.stats.retries.Load(),
+		Successes:      p.stats.successes.Load(),
+		NotFound:       p.stats.notFound.Load(),
+		Failures:       p.stats.failures.Load(),
+		BreakerRejects: p.stats.breakerRejects.Load(),
+		BreakerState:   p.breaker.State().String(),
+	}
+}
+
+// Ping проверяет, что внешний API отвечает. Запрос идёт в обход кэша, повторов и выключателя
+// и не учитывается в счётчиках. Доступным считается API, ответивший кодом ниже 500
+func (p *HTTPProvider) Ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.baseURL+"/info", nil)
+	if err != nil {
+		return err
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("внешний API вернул статус %d", resp.StatusCode)
+	}
+	return nil
+}
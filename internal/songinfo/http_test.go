@@ -0,0 +1,276 @@
+package songinfo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"songs/pkg/resilience"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const detailJSON = `{"releaseDate":"16.07.2006","text":"Ooh baby, don't you know I suffer?","link":"https://www.youtube.com/watch?v=Xsp3_a-PMTw"}`
+
+// reply ответ тестового сервера внешнего API
+type reply struct {
+	status int
+	body   string
+}
+
+// scriptedAPI отдаёт ответы replies по очереди, после их окончания повторяет последний
+type scriptedAPI struct {
+	replies []reply
+	calls   atomic.Int64
+}
+
+func (a *scriptedAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	n := int(a.calls.Add(1))
+	resp := a.replies[min(n, len(a.replies))-1]
+	w.WriteHeader(resp.status)
+	w.Write([]byte(resp.body))
+}
+
+func newTestProvider(t *testing.T, api http.Handler, opts HTTPOptions) *HTTPProvider {
+	t.Helper()
+	srv := httptest.NewServer(api)
+	t.Cleanup(srv.Close)
+	opts.Transport = srv.Client().Transport
+	if opts.Timeout == 0 {
+		opts.Timeout = 5 * time.Second
+	}
+	return NewHTTPProvider(srv.URL, opts)
+}
+
+var testRetry = resilience.Backoff{Attempts: 3, Initial: time.Millisecond, Max: 2 * time.Millisecond}
+
+func TestHTTPProviderRetry(t *testing.T) {
+	ok := reply{http.StatusOK, detailJSON}
+	tests := []struct {
+		name      string
+		replies   []reply
+		wantErr   bool
+		notFound  bool
+		wantCalls int64
+		wantStats Stats
+	}{
+		{
+			name:      "успех с первой попытки",
+			replies:   []reply{ok},
+			wantCalls: 1,
+			wantStats: Stats{Requests: 1, CacheMisses: 1, Attempts: 1, Successes: 1, BreakerState: "closed"},
+		},
+		{
+			name:      "повтор после ответов 5xx",
+			replies:   []reply{{http.StatusInternalServerError, ""}, {http.StatusServiceUnavailable, ""}, ok},
+			wantCalls: 3,
+			wantStats: Stats{Requests: 1, CacheMisses: 1, Attempts: 3, Retries: 2, Successes: 1, BreakerState: "closed"},
+		},
+		{
+			name:      "попытки исчерпаны",
+			replies:   []reply{{http.StatusBadGateway, ""}},
+			wantErr:   true,
+			wantCalls: 3,
+			wantStats: Stats{Requests: 1, CacheMisses: 1, Attempts: 3, Retries: 2, Failures: 1, BreakerState: "closed"},
+		},
+		{
+			name:      "404 не повторяется",
+			replies:   []reply{{http.StatusNotFound, ""}},
+			wantErr:   true,
+			notFound:  true,
+			wantCalls: 1,
+			wantStats: Stats{Requests: 1, CacheMisses: 1, Attempts: 1, NotFound: 1, BreakerState: "closed"},
+		},
+		{
+			name:      "ответ 4xx не повторяется",
+			replies:   []reply{{http.StatusBadRequest, ""}},
+			wantErr:   true,
+			wantCalls: 1,
+			wantStats: Stats{Requests: 1, CacheMisses: 1, Attempts: 1, Failures: 1, BreakerState: "closed"},
+		},
+		{
+			name:      "некорректный JSON не повторяется",
+			replies:   []reply{{http.StatusOK, "{"}},
+			wantErr:   true,
+			wantCalls: 1,
+			wantStats: Stats{Requests: 1, CacheMisses: 1, Attempts: 1, Failures: 1, BreakerState: "closed"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := &scriptedAPI{replies: tt.replies}
+			p := newTestProvider(t, api, HTTPOptions{Retry: testRetry, BreakerThreshold: 5, BreakerCooldown: time.Minute})
+
+			detail, err := p.GetSongInfo(context.Background(), "Muse", "Supermassive Black Hole")
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("ошибка %v, ожидается ошибка: %v", err, tt.wantErr)
+			}
+			if tt.notFound != errors.Is(err, ErrNotFound) {
+				t.Fatalf("ошибка %v, ожидается ErrNotFound: %v", err, tt.notFound)
+			}
+			if err == nil && detail.ReleaseDate != "16.07.2006" {
+				t.Fatalf("неожиданный ответ: %+v", detail)
+			}
+			if calls := api.calls.Load(); calls != tt.wantCalls {
+				t.Fatalf("обращений к API %d, ожидается %d", calls, tt.wantCalls)
+			}
+			if stats := p.Stats(); stats != tt.wantStats {
+				t.Fatalf("счётчики %+v, ожидаются %+v", stats, tt.wantStats)
+			}
+		})
+	}
+}
+
+func TestHTTPProviderRetriesNetworkErrors(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close()
+	p := NewHTTPProvider(srv.URL, HTTPOptions{Timeout: time.Second, ConnectTimeout: time.Second, Retry: testRetry, BreakerThreshold: 5})
+
+	if _, err := p.GetSongInfo(context.Background(), "Muse", "Hysteria"); err == nil || errors.Is(err, ErrNotFound) {
+		t.Fatalf("ошибка %v, ожидается сетевая ошибка", err)
+	}
+	want := Stats{Requests: 1, CacheMisses: 1, Attempts: 3, Retries: 2, Failures: 1, BreakerState: "closed"}
+	if stats := p.Stats(); stats != want {
+		t.Fatalf("счётчики %+v, ожидаются %+v", stats, want)
+	}
+}
+
+func TestHTTPProviderBreaker(t *testing.T) {
+	const cooldown = 50 * time.Millisecond
+	api := &scriptedAPI{replies: []reply{
+		{http.StatusInternalServerError, ""},
+		{http.StatusInternalServerError, ""},
+		{http.StatusOK, detailJSON},
+	}}
+	p := newTestProvider(t, api, HTTPOptions{Retry: resilience.Backoff{Attempts: 1}, BreakerThreshold: 2, BreakerCooldown: cooldown})
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if _, err := p.GetSongInfo(ctx, "Muse", "Hysteria"); err == nil {
+			t.Fatalf("запрос %d: ожидается ошибка", i+1)
+		}
+	}
+	if state := p.Stats().BreakerState; state != "open" {
+		t.Fatalf("состояние выключателя %s, ожидается open", state)
+	}
+
+	// Разомкнутый выключатель отклоняет запрос без обращения к API
+	if _, err := p.GetSongInfo(ctx, "Muse", "Hysteria"); !errors.Is(err, resilience.ErrCircuitOpen) {
+		t.Fatalf("ошибка %v, ожидается %v", err, resilience.ErrCircuitOpen)
+	}
+	if calls := api.calls.Load(); calls != 2 {
+		t.Fatalf("обращений к API %d, ожидается 2", calls)
+	}
+
+	// После паузы пробный запрос проходит и замыкает выключатель
+	time.Sleep(cooldown)
+	if _, err := p.GetSongInfo(ctx, "Muse", "Hysteria"); err != nil {
+		t.Fatalf("пробный запрос: %v", err)
+	}
+	want := Stats{Requests: 4, CacheMisses: 4, Attempts: 3, Successes: 1, Failures: 3, BreakerRejects: 1, BreakerState: "closed"}
+	if stats := p.Stats(); stats != want {
+		t.Fatalf("счётчики %+v, ожидаются %+v", stats, want)
+	}
+}
+
+func TestHTTPProviderCancelReleasesProbe(t *testing.T) {
+	const cooldown = 50 * time.Millisecond
+	var calls atomic.Int64
+	received := make(chan struct{}, 1)
+	api := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch calls.Add(1) {
+		case 1:
+			w.WriteHeader(http.StatusInternalServerError)
+		case 2:
+			// Пробный запрос зависает, пока клиент его не отменит
+			received <- struct{}{}
+			<-r.Context().Done()
+		default:
+			w.Write([]byte(detailJSON))
+		}
+	})
+	p := newTestProvider(t, api, HTTPOptions{Retry: testRetry, BreakerThreshold: 1, BreakerCooldown: cooldown})
+
+	if _, err := p.GetSongInfo(context.Background(), "Muse", "Hysteria"); err == nil {
+		t.Fatal("ожидается ошибка")
+	}
+	if state := p.Stats().BreakerState; state != "open" {
+		t.Fatalf("состояние выключателя %s, ожидается open", state)
+	}
+	time.Sleep(cooldown)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-received
+		cancel()
+	}()
+	if _, err := p.GetSongInfo(ctx, "Muse", "Hysteria"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("ошибка %v, ожидается %v", err, context.Canceled)
+	}
+	// Отменённая проба не размыкает выключатель и не занимает место следующей пробы
+	if state := p.Stats().BreakerState; state != "half-open" {
+		t.Fatalf("состояние выключателя после отмены %s, ожидается half-open", state)
+	}
+	if _, err := p.GetSongInfo(context.Background(), "Muse", "Hysteria"); err != nil {
+		t.Fatalf("следующая проба: %v", err)
+	}
+	if state := p.Stats().BreakerState; state != "closed" {
+		t.Fatalf("состояние выключателя %s, ожидается closed", state)
+	}
+}
+
+func TestHTTPProviderCache(t *testing.T) {
+	const ttl = 50 * time.Millisecond
+	api := &scriptedAPI{replies: []reply{{http.StatusOK, detailJSON}}}
+	p := newTestProvider(t, api, HTTPOptions{Retry: testRetry, BreakerThreshold: 5, CacheTTL: ttl, CacheSize: 1})
+	ctx := context.Background()
+
+	get := func(song string) {
+		t.Helper()
+		if _, err := p.GetSongInfo(ctx, "Muse", song); err != nil {
+			t.Fatalf("GetSongInfo(%s): %v", song, err)
+		}
+	}
+	expectCalls := func(want int64) {
+		t.Helper()
+		if calls := api.calls.Load(); calls != want {
+			t.Fatalf("обращений к API %d, ожидается %d", calls, want)
+		}
+	}
+
+	get("Hysteria")
+	get("Hysteria")
+	expectCalls(1)
+
+	// Кэш на одну запись: новая песня вытесняет предыдущую
+	get("Uprising")
+	get("Hysteria")
+	expectCalls(3)
+
+	// Устаревшая запись запрашивается заново
+	time.Sleep(ttl + 10*time.Millisecond)
+	get("Hysteria")
+	expectCalls(4)
+
+	want := Stats{Requests: 5, CacheHits: 1, CacheMisses: 4, Attempts: 4, Successes: 4, BreakerState: "closed"}
+	if stats := p.Stats(); stats != want {
+		t.Fatalf("счётчики %+v, ожидаются %+v", stats, want)
+	}
+}
+
+func TestHTTPProviderDoesNotCacheNotFound(t *testing.T) {
+	api := &scriptedAPI{replies: []reply{{http.StatusNotFound, ""}, {http.StatusOK, detailJSON}}}
+	p := newTestProvider(t, api, HTTPOptions{Retry: testRetry, BreakerThreshold: 5, CacheTTL: time.Minute, CacheSize: 10})
+
+	if _, err := p.GetSongInfo(context.Background(), "Muse", "Hysteria"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("ошибка %v, ожидается %v", err, ErrNotFound)
+	}
+	if _, err := p.GetSongInfo(context.Background(), "Muse", "Hysteria"); err != nil {
+		t.Fatalf("повторный запрос: %v", err)
+	}
+	if calls := api.calls.Load(); calls != 2 {
+		t.Fatalf("обращений к API %d, ожидается 2", calls)
+	}
+}
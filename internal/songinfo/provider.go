@@ -0,0 +1,108 @@
+package songinfo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"songs/internal/config"
+	"songs/internal/storages"
+	"songs/pkg/resilience"
+	"strings"
+)
+
+// ErrNotFound возвращается провайдером, если у него нет информации о запрошенной песне
+var ErrNotFound = errors.New("информация о песне не найдена")
+
+// SongInfoProvider источник дополнительной информации о песне (дата выхода, текст, ссылка)
+type SongInfoProvider interface {
+	GetSongInfo(ctx context.Context, group string, song string) (*storages.SongDetail, error)
+}
+
+// Chain опрашивает провайдеров по порядку и возвращает первый найденный результат.
+// Промах или ошибка одного провайдера передают запрос следующему.
+type Chain []SongInfoProvider
+
+func (c Chain) GetSongInfo(ctx context.Context, group string, song string) (*storages.SongDetail, error) {
+	var errs []error
+	for _, provider := range c {
+		detail, err := provider.GetSongInfo(ctx, group, song)
+		if err == nil {
+			return detail, nil
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if !errors.Is(err, ErrNotFound) {
+			errs = append(errs, err)
+		}
+	}
+	// Если ни один провайдер не вернул ошибку, значит песня просто не найдена
+	if len(errs) == 0 {
+		return nil, ErrNotFound
+	}
+	return nil, errors.Join(errs...)
+}
+
+// NewFromConfig собирает цепочку провайдеров в порядке, указанном в конфигурации
+func NewFromConfig(cfg *config.Config) (SongInfoProvider, error) {
+	var chain Chain
+	for _, name := range cfg.SongInfo.Providers {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "http":
+			if cfg.ExternalAPI.Address == "" {
+				return nil, errors.New("для провайдера http необходимо указать EXTERNAL_API_ADDRESS")
+			}
+			chain = append(chain, NewHTTPProvider(cfg.ExternalAPI.Address, HTTPOptions{
+				Timeout:        cfg.ExternalAPI.Timeout,
+				ConnectTimeout: cfg.ExternalAPI.ConnectTimeout,
+				Retry: resilience.Backoff{
+					Attempts: cfg.ExternalAPI.RetryAttempts,
+					Initial:  cfg.ExternalAPI.RetryInitial,
+					Max:      cfg.ExternalAPI.RetryMax,
+				},
+				BreakerThreshold: cfg.ExternalAPI.BreakerThreshold,
+				BreakerCooldown:  cfg.ExternalAPI.BreakerCooldown,
+				CacheTTL:         cfg.ExternalAPI.CacheTTL,
+				CacheSize:        cfg.ExternalAPI.CacheSize,
+			}))
+		case "catalog":
+			provider, err := NewCatalogProvider(cfg.SongInfo.CatalogPath)
+			if err != nil {
+				return nil, err
+			}
+			chain = append(chain, provider)
+		case "":
+			continue
+		default:
+			return nil, fmt.Errorf("неизвестный провайдер информации о песнях: %s", name)
+		}
+	}
+	if len(chain) == 0 {
+		return nil, errors.New("не задан ни один провайдер информации о песнях")
+	}
+	if len(chain) == 1 {
+		return chain[0], nil
+	}
+	return chain, nil
+}
+
+// HTTPProviders возвращает клиенты внешнего API из провайдера, собранного NewFromConfig,
+// например чтобы публиковать их статистику
+func HTTPProviders(provider SongInfoProvider) []*HTTPProvider {
+	switch p := provider.(type) {
+	case *HTTPProvider:
+		return []*HTTPProvider{p}
+	case Chain:
+		var providers []*HTTPProvider
+		for _, next := range p {
+			providers = append(providers, HTTPProviders(next)...)
+		}
+		return providers
+	}
+	return nil
+}
+
+// key нормализует пару (группа, песня) для поиска без учёта регистра и пробелов по краям
+func key(group string, song string) string {
+	return strings.ToLower(strings.TrimSpace(group)) + "\x00" + strings.ToLower(strings.TrimSpace(song))
+}
@@ -0,0 +1,23 @@
+package storages
+
+import "time"
+
+// APIKey ключ доступа для машинных клиентов. Сам ключ не хранится, только его хэш
+type APIKey struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	// Начало ключа, по которому его можно узнать
+	Prefix string `json:"prefix"`
+	// Области доступа: read, write, admin
+	Scopes     []string   `json:"scopes"`
+	CreatedBy  string     `json:"created_by"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// Active сообщает, можно ли ещё пользоваться ключом
+func (k APIKey) Active(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || k.ExpiresAt.After(now))
+}
@@ -0,0 +1,41 @@
+package storages
+
+import "time"
+
+// Действия, которые фиксируются в журнале изменений песни
+const (
+	AuditCreate      = "create"
+	AuditUpdate      = "update"
+	AuditPatch       = "patch"
+	AuditDelete      = "delete"
+	AuditRestore     = "restore"
+	AuditPurge       = "purge"
+	AuditLyricsAdd   = "lyrics_add"
+	AuditEnrichment  = "enrichment"
+	AuditRevert      = "revert"
+	AuditGroupRename = "group_rename"
+)
+
+// ActorEnrichment автор изменений, которые вносит фоновое обогащение
+const ActorEnrichment = "system:enrichment"
+
+// SongSnapshot состояние песни вместе с текстом на момент изменения
+type SongSnapshot struct {
+	Song
+	Verses []Verse `json:"verses"`
+}
+
+// AuditEntry запись журнала изменений песни. Before отсутствует у созданной песни,
+// After — у окончательно удалённой
+type AuditEntry struct {
+	// ID записи служит номером ревизии, к которой можно откатить песню
+	ID     int64  `json:"id"`
+	SongID int    `json:"song_id"`
+	Action string `json:"action"`
+	Actor  string `json:"actor"`
+	// Версия песни после изменения
+	Version   int           `json:"version"`
+	Before    *SongSnapshot `json:"before"`
+	After     *SongSnapshot `json:"after"`
+	CreatedAt time.Time     `json:"created_at"`
+}
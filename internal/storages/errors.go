@@ -0,0 +1,93 @@
+package storages
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrNotFound возвращается хранилищем, если запрошенная запись не существует
+var ErrNotFound = errors.New("запись не найдена")
+
+// ErrVersionMismatch возвращается при изменении записи, если её версия отличается от ожидаемой,
+// то есть запись уже изменил кто-то другой
+var ErrVersionMismatch = errors.New("версия записи не совпадает с ожидаемой")
+
+// ValidationError ошибка проверки значения поля
+type ValidationError struct {
+	Field   string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+// ValidationErrors ошибки проверки нескольких полей
+type ValidationErrors []*ValidationError
+
+func (e ValidationErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// AsValidationErrors извлекает ошибки полей из err, если это ошибка проверки
+func AsValidationErrors(err error) (ValidationErrors, bool) {
+	var list ValidationErrors
+	if errors.As(err, &list) {
+		return list, true
+	}
+	var single *ValidationError
+	if errors.As(err, &single) {
+		return ValidationErrors{single}, true
+	}
+	return nil, false
+}
+
+// ErrConflict возвращается, если запись с таким уникальным значением уже существует
+var ErrConflict = errors.New("запись с таким значением уже существует")
+
+// ErrReferenced возвращается при удалении записи, на которую ссылаются другие записи
+var ErrReferenced = errors.New("запись используется другими записями")
+
+// ErrMissingReference возвращается, если запись ссылается на несуществующую запись
+var ErrMissingReference = errors.New("связанная запись не существует")
+
+// ErrTimeout возвращается, если запрос к базе прерван по истечении отведённого ему времени
+var ErrTimeout = errors.New("превышено время выполнения запроса к базе данных")
+
+// ErrJobReleased возвращается при завершении задачи обогащения, которая больше не принадлежит воркеру:
+// её перезапустили, аренда истекла и задачу захватил другой воркер, или песня удалена в корзину
+var ErrJobReleased = errors.New("задача обогащения больше не принадлежит воркеру")
+
+// ConstraintError нарушение ограничения целостности. Через errors.Is сравнивается
+// с ErrConflict, ErrReferenced или ErrMissingReference
+type ConstraintError struct {
+	Err error
+	// Имя нарушенного ограничения, нужно для журнала и не предназначено для клиента
+	Constraint string
+}
+
+func (e *ConstraintError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ConstraintError) Unwrap() error {
+	return e.Err
+}
+
+// IsRejection сообщает, что операция отклонена из-за данных запроса: запись не найдена, версия
+// не совпадает, нарушено ограничение или значение недопустимо. Такие ошибки не означают сбоя хранилища
+func IsRejection(err error) bool {
+	if _, ok := AsValidationErrors(err); ok {
+		return true
+	}
+	for _, target := range []error{ErrNotFound, ErrVersionMismatch, ErrInvalidCursor, ErrConflict, ErrReferenced, ErrMissingReference, ErrJobReleased} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,102 @@
+package storages
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Режимы сопоставления строковых фильтров
+const (
+	MatchContains = "contains"
+	MatchExact    = "exact"
+	MatchPrefix   = "prefix"
+)
+
+// TextFilter строковый фильтр без учёта регистра. Пустое значение не фильтрует
+type TextFilter struct {
+	Value string
+	Match string
+}
+
+// ParseMatch проверяет режим сопоставления, пустой режим означает contains
+func ParseMatch(match string) (string, error) {
+	switch match {
+	case "", MatchContains:
+		return MatchContains, nil
+	case MatchExact, MatchPrefix:
+		return match, nil
+	default:
+		return "", fmt.Errorf("неизвестный режим сопоставления %q, допустимы: contains, exact, prefix", match)
+	}
+}
+
+// SortField поле сортировки и её направление
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+// SongSortFields поля, по которым разрешена сортировка списка песен
+var SongSortFields = []string{"id", "group_id", "group", "song", "release_date", "link", "enrichment_status", "version", "updated_at", "deleted_at"}
+
+// ParseSort разбирает параметр сортировки вида "release_date,-song".
+// Минус перед полем означает сортировку по убыванию. Допускаются только поля из SongSortFields
+func ParseSort(value string) ([]SortField, error) {
+	var fields []SortField
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		field := SortField{Field: strings.TrimPrefix(strings.TrimPrefix(part, "-"), "+"), Desc: strings.HasPrefix(part, "-")}
+		// Поддерживаем то же имя поля, что и в JSON песни
+		if field.Field == "releaseDate" {
+			field.Field = "release_date"
+		}
+		if !isSongSortField(field.Field) {
+			return nil, fmt.Errorf("сортировка по полю %q не поддерживается, допустимы: %s", field.Field, strings.Join(SongSortFields, ", "))
+		}
+		if seen[field.Field] {
+			continue
+		}
+		seen[field.Field] = true
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+func isSongSortField(field string) bool {
+	for _, f := range SongSortFields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// SongFilter условия отбора и сортировки списка песен. Нулевые значения полей не фильтруют
+type SongFilter struct {
+	ID      int
+	GroupID int
+	Group   TextFilter
+	Song    TextFilter
+	Link    TextFilter
+	// Точная дата выхода и границы диапазона включительно в формате YYYY-MM-DD
+	ReleaseDate     string
+	ReleaseDateFrom string
+	ReleaseDateTo   string
+	// Статус обогащения: pending, done или failed
+	EnrichmentStatus string
+	// Точная версия песни
+	Version int
+	// Границы времени последнего изменения включительно
+	UpdatedFrom time.Time
+	UpdatedTo   time.Time
+	// Выбирать песни из корзины вместо активных
+	Trashed bool
+	// Порядок сортировки. Для стабильности страниц сортировка всегда дополняется полем id
+	Sort []SortField
+}
@@ -1,21 +1,141 @@
 package storages
 
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
 type Group struct {
 	ID   int    `json:"id"`
 	Name string `json:"name"`
 }
 
 type Song struct {
-	ID          int    `json:"id"`
-	GroupID     int    `json:"group_id"`
-	Group       string `json:"group"`
-	Name        string `json:"song"`
+	ID               int    `json:"id"`
+	GroupID          int    `json:"group_id"`
+	Group            string `json:"group"`
+	Name             string `json:"song"`
+	ReleaseDate      string `json:"releaseDate"`
+	Link             string `json:"link"`
+	EnrichmentStatus string `json:"enrichment_status"`
+	// Версия увеличивается при каждом изменении песни и служит для оптимистичной блокировки
+	Version   int       `json:"version"`
+	UpdatedAt time.Time `json:"updated_at"`
+	// Момент удаления в корзину, у активных песен не заполняется
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+}
+
+// Статусы обогащения песни данными из внешних источников
+const (
+	EnrichmentPending = "pending"
+	EnrichmentDone    = "done"
+	EnrichmentFailed  = "failed"
+)
+
+// Статусы задачи обогащения в очереди
+const (
+	JobPending = "pending"
+	JobRunning = "running"
+	JobDone    = "done"
+	JobDead    = "dead"
+)
+
+// EnrichmentJob задача фонового обогащения песни
+type EnrichmentJob struct {
+	ID        int       `json:"id"`
+	SongID    int       `json:"song_id"`
+	Group     string    `json:"group"`
+	Song      string    `json:"song"`
+	Status    string    `json:"status"`
+	Attempts  int       `json:"attempts"`
+	NextRunAt time.Time `json:"next_run_at"`
+	LastError string    `json:"last_error,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// Версия песни на момент захвата задачи: по ней видно, меняли ли песню, пока шёл запрос
+	SongVersion int `json:"-"`
+}
+
+// SongDetail описывает ответ внешнего API.
+// Text содержит полный текст песни, куплеты разделены пустой строкой.
+type SongDetail struct {
 	ReleaseDate string `json:"releaseDate"`
+	Text        string `json:"text"`
 	Link        string `json:"link"`
 }
 
-type SongDetail struct {
-	ReleaseDate string   `json:"releaseDate"`
-	Text        []string `json:"text"`
-	Link        string   `json:"link"`
+// SearchLanguages конфигурации полнотекстового поиска, для которых построены индексы
+var SearchLanguages = []string{"russian", "english", "simple"}
+
+// IsSearchLanguage проверяет, поддерживается ли конфигурация полнотекстового поиска
+func IsSearchLanguage(language string) bool {
+	for _, l := range SearchLanguages {
+		if l == language {
+			return true
+		}
+	}
+	return false
+}
+
+// SearchMatch строка текста, совпавшая с поисковым запросом.
+// Text экранирован для HTML, совпавшие слова обрамлены тегами <mark></mark>
+type SearchMatch struct {
+	Verse int    `json:"verse"`
+	Line  int    `json:"line"`
+	Text  string `json:"text"`
+}
+
+// SearchResult песня, найденная по тексту, с релевантностью и совпавшими строками
+type SearchResult struct {
+	SongID  int           `json:"song_id"`
+	Group   string        `json:"group"`
+	Song    string        `json:"song"`
+	Rank    float64       `json:"rank"`
+	Matches []SearchMatch `json:"matches"`
+}
+
+// releaseDateLayouts допустимые форматы даты выхода песни
+var releaseDateLayouts = []string{"2006-01-02", "02.01.2006"}
+
+// ParseReleaseDate разбирает дату выхода в формате YYYY-MM-DD или DD.MM.YYYY
+func ParseReleaseDate(value string) (time.Time, error) {
+	for _, layout := range releaseDateLayouts {
+		if t, err := time.Parse(layout, strings.TrimSpace(value)); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("неверный формат даты %q, ожидается YYYY-MM-DD или DD.MM.YYYY", value)
+}
+
+// Verse куплет песни с упорядоченными строками
+type Verse struct {
+	Number int      `json:"verse"`
+	Lines  []string `json:"lines"`
+}
+
+// Verses разбивает текст песни на куплеты по пустым строкам.
+// Куплеты нумеруются с единицы, пустые строки по краям отбрасываются.
+func (d SongDetail) Verses() []Verse {
+	text := strings.ReplaceAll(d.Text, "\r\n", "\n")
+
+	var verses []Verse
+	var lines []string
+	flush := func() {
+		if len(lines) > 0 {
+			verses = append(verses, Verse{Number: len(verses) + 1, Lines: lines})
+			lines = nil
+		}
+	}
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			flush()
+			continue
+		}
+		lines = append(lines, line)
+	}
+	flush()
+	return verses
 }
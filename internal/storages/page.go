@@ -0,0 +1,72 @@
+package storages
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+)
+
+// ErrInvalidCursor возвращается, если курсор пагинации повреждён или выдан для другой сортировки
+var ErrInvalidCursor = errors.New("неверный курсор пагинации")
+
+// Pagination параметры запроса страницы. Если задан Cursor, номер страницы игнорируется
+// и используется пагинация по ключу (keyset), иначе страница выбирается через OFFSET
+type Pagination struct {
+	Page   int
+	Limit  int
+	Cursor string
+}
+
+// PageInfo общая часть конверта ответа со списком
+type PageInfo struct {
+	// Общее количество элементов, удовлетворяющих фильтру
+	Total int `json:"total"`
+	// Есть ли элементы после текущей страницы
+	HasMore bool `json:"has_more"`
+	// Непрозрачные курсоры следующей и предыдущей страниц
+	Next string `json:"next,omitempty"`
+	Prev string `json:"prev,omitempty"`
+	// Номер страницы, если запрос был постраничным, а не по курсору
+	Page  int `json:"page,omitempty"`
+	Limit int `json:"limit"`
+}
+
+// SongsPage страница списка песен
+type SongsPage struct {
+	Items []Song `json:"items"`
+	PageInfo
+}
+
+// VersesPage страница текста песни, элементом которой является куплет
+type VersesPage struct {
+	Items []Verse `json:"items"`
+	PageInfo
+}
+
+// Cursor позиция в упорядоченном списке: значения ключей сортировки последнего
+// (или первого, при движении назад) элемента страницы
+type Cursor struct {
+	Keys     []string `json:"k"`
+	Backward bool     `json:"b,omitempty"`
+	// Сортировка, для которой выдан курсор. Курсор нельзя применить к другому порядку
+	Sort string `json:"s,omitempty"`
+}
+
+// Encode кодирует курсор в непрозрачную строку для клиента
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor восстанавливает курсор из строки, полученной от клиента
+func DecodeCursor(value string) (Cursor, error) {
+	var c Cursor
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return c, ErrInvalidCursor
+	}
+	if err := json.Unmarshal(data, &c); err != nil {
+		return c, ErrInvalidCursor
+	}
+	return c, nil
+}
@@ -0,0 +1,76 @@
+package storages
+
+import (
+	"net/url"
+	"strings"
+	"unicode/utf8"
+)
+
+// SongPatch частичное обновление песни. nil означает, что поле не меняется.
+// Пустая строка в ReleaseDate или Link очищает значение
+type SongPatch struct {
+	Group       *string
+	Name        *string
+	ReleaseDate *string
+	Link        *string
+}
+
+// IsEmpty сообщает, что патч не меняет ни одного поля
+func (p SongPatch) IsEmpty() bool {
+	return p.Group == nil && p.Name == nil && p.ReleaseDate == nil && p.Link == nil
+}
+
+// Normalize проверяет значения полей патча и приводит их к виду, в котором они хранятся:
+// обрезает пробелы и переводит дату выхода в формат YYYY-MM-DD.
+// Возвращает ValidationErrors со всеми неверными полями
+func (p *SongPatch) Normalize() error {
+	var errs ValidationErrors
+	for _, field := range []struct {
+		name  string
+		value *string
+	}{{"group", p.Group}, {"song", p.Name}} {
+		if field.value == nil {
+			continue
+		}
+		*field.value = strings.TrimSpace(*field.value)
+		if *field.value == "" {
+			errs = append(errs, &ValidationError{Field: field.name, Message: "значение не может быть пустым"})
+		} else if utf8.RuneCountInString(*field.value) > 255 {
+			errs = append(errs, &ValidationError{Field: field.name, Message: "значение длиннее 255 символов"})
+		}
+	}
+
+	if p.ReleaseDate != nil && *p.ReleaseDate != "" {
+		date, err := ParseReleaseDate(*p.ReleaseDate)
+		if err != nil {
+			errs = append(errs, &ValidationError{Field: "releaseDate", Message: err.Error()})
+		} else {
+			*p.ReleaseDate = date.Format("2006-01-02")
+		}
+	}
+
+	if p.Link != nil && *p.Link != "" {
+		*p.Link = strings.TrimSpace(*p.Link)
+		if err := ValidateLink(*p.Link); err != nil {
+			errs = append(errs, err.(*ValidationError))
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// ValidateLink проверяет, что ссылка является абсолютным URL со схемой http или https
+func ValidateLink(link string) error {
+	u, err := url.ParseRequestURI(link)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return &ValidationError{Field: "link", Message: "ожидается абсолютный URL со схемой http или https"}
+	}
+	// Столбец VARCHAR(255) ограничивает длину в символах, а не в байтах
+	if utf8.RuneCountInString(link) > 255 {
+		return &ValidationError{Field: "link", Message: "значение длиннее 255 символов"}
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/lib/pq"
+	"songs/internal/storages"
+	"time"
+)
+
+// lastUsedPrecision точность отметки последнего использования ключа.
+// Отметка обновляется не чаще, чтобы каждый запрос клиента не приводил к записи в базу
+const lastUsedPrecision = time.Minute
+
+const apiKeyColumns = `id, name, prefix, scopes, created_by, created_at, expires_at, last_used_at, revoked_at`
+
+func scanAPIKey(row interface{ Scan(...any) error }) (storages.APIKey, error) {
+	var key storages.APIKey
+	err := row.Scan(&key.ID, &key.Name, &key.Prefix, pq.Array(&key.Scopes), &key.CreatedBy,
+		&key.CreatedAt, &key.ExpiresAt, &key.LastUsedAt, &key.RevokedAt)
+	return key, err
+}
+
+func (s *PostgresStorage) CreateAPIKey(ctx context.Context, key storages.APIKey, hash string) (storages.APIKey, error) {
+	ctx, cancel := s.withTimeout(ctx, s.timeouts.Write)
+	defer cancel()
+
+	query := `
+        INSERT INTO api_keys (name, prefix, key_hash, scopes, created_by, expires_at)
+        VALUES ($1, $2, $3, $4, $5, $6)
+        RETURNING ` + apiKeyColumns + `;
+    `
+	created, err := scanAPIKey(s.db.QueryRowContext(ctx, query, key.Name, key.Prefix, hash, pq.Array(key.Scopes), key.CreatedBy, key.ExpiresAt))
+	if err != nil {
+		s.log(ctx).Printf("Ошибка при создании ключа API (%s): %v", key.Name, err)
+		return created, translateError(err)
+	}
+	s.log(ctx).Printf("Создан ключ API (ID: %d, имя: %s)", created.ID, created.Name)
+	return created, nil
+}
+
+func (s *PostgresStorage) GetAPIKeys(ctx context.Context, page int, limit int) ([]storages.APIKey, error) {
+	ctx, cancel := s.withTimeout(ctx, s.timeouts.Read)
+	defer cancel()
+
+	offset := (page - 1) * limit
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys ORDER BY id LIMIT $1 OFFSET $2`
+	rows, err := s.db.QueryContext(ctx, query, limit, offset)
+	if err != nil {
+		s.log(ctx).Printf("Ошибка при получении ключей API: %v", err)
+		return nil, translateError(err)
+	}
+	defer rows.Close()
+
+	keys := []storages.APIKey{}
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			s.log(ctx).Printf("Ошибка при сканировании ключа API: %v", err)
+			return nil, translateError(err)
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+func (s *PostgresStorage) RevokeAPIKey(ctx context.Context, id int) error {
+	ctx, cancel := s.withTimeout(ctx, s.timeouts.Write)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, `UPDATE api_keys SET revoked_at = now() WHERE id = $1 AND revoked_at IS NULL`, id)
+	if err != nil {
+		s.log(ctx).Printf("Ошибка при отзыве ключа API (ID: %d): %v", id, err)
+		return translateError(err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return storages.ErrNotFound
+	}
+	s.log(ctx).Printf("Ключ API отозван (ID: %d)", id)
+	return nil
+}
+
+func (s *PostgresStorage) AuthenticateAPIKey(ctx context.Context, hash string) (storages.APIKey, error) {
+	ctx, cancel := s.withTimeout(ctx, s.timeouts.Read)
+	defer cancel()
+
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE key_hash = $1`
+	key, err := scanAPIKey(s.db.QueryRowContext(ctx, query, hash))
+	if errors.Is(err, sql.ErrNoRows) {
+		return key, storages.ErrNotFound
+	}
+	if err != nil {
+		s.log(ctx).Printf("Ошибка при проверке ключа API: %v", err)
+		return key, translateError(err)
+	}
+
+	now := time.Now()
+	if !key.Active(now) {
+		return storages.APIKey{}, storages.ErrNotFound
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= lastUsedPrecision {
+		if _, err := s.db.ExecContext(ctx, `UPDATE api_keys SET last_used_at = now() WHERE id = $1`, key.ID); err != nil {
+			// Ключ действителен, поэтому неудачная отметка использования не мешает запросу
+			s.log(ctx).Printf("Ошибка при обновлении времени использования ключа API (ID: %d): %v", key.ID, err)
+		} else {
+			key.LastUsedAt = &now
+		}
+	}
+	return key, nil
+}
@@ -0,0 +1,231 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"songs/internal/storages"
+)
+
+// querier общая часть *sql.DB и *sql.Tx, достаточная для чтения
+type querier interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// songSnapshot читает песню вместе с текстом, включая песни из корзины.
+// Для несуществующей песни возвращает nil без ошибки
+func songSnapshot(ctx context.Context, q querier, id int) (*storages.SongSnapshot, error) {
+	snapshot := &storages.SongSnapshot{Verses: []storages.Verse{}}
+	song := &snapshot.Song
+	query := `
+        SELECT s.id, s.group_id, g.name, s.name, COALESCE(s.release_date::text, ''), COALESCE(s.link, ''), s.enrichment_status, s.version, s.updated_at, s.deleted_at
+        FROM songs s
+        JOIN groups g ON s.group_id = g.id
+        WHERE s.id = $1;
+    `
+	err := q.QueryRowContext(ctx, query, id).Scan(&song.ID, &song.GroupID, &song.Group, &song.Name, &song.ReleaseDate, &song.Link, &song.EnrichmentStatus, &song.Version, &song.UpdatedAt, &song.DeletedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := q.QueryContext(ctx, `SELECT verse_number, lyrics_line FROM song_lyrics WHERE song_id = $1 ORDER BY verse_number, line_number`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var number int
+		var line string
+		if err := rows.Scan(&number, &line); err != nil {
+			return nil, err
+		}
+		if n := len(snapshot.Verses); n == 0 || snapshot.Verses[n-1].Number != number {
+			snapshot.Verses = append(snapshot.Verses, storages.Verse{Number: number})
+		}
+		last := &snapshot.Verses[len(snapshot.Verses)-1]
+		last.Lines = append(last.Lines, line)
+	}
+	return snapshot, rows.Err()
+}
+
+// recordChange дописывает в журнал изменение песни в рамках транзакции, выполнившей это изменение.
+// Состояние после изменения читается из той же транзакции
+func recordChange(ctx context.Context, tx *sql.Tx, songID int, action string, actor string, before *storages.SongSnapshot) error {
+	after, err := songSnapshot(ctx, tx, songID)
+	if err != nil {
+		return err
+	}
+
+	version := 0
+	if after != nil {
+		version = after.Version
+	} else if before != nil {
+		version = before.Version
+	}
+
+	query := `
+        INSERT INTO song_audit (song_id, action, actor, version, before, after)
+        VALUES ($1, $2, $3, $4, $5, $6);
+    `
+	_, err = tx.ExecContext(ctx, query, songID, action, actor, version, snapshotJSON(before), snapshotJSON(after))
+	return err
+}
+
+// snapshotJSON сериализует снимок для столбца JSONB, отсутствующий снимок сохраняется как NULL
+func snapshotJSON(snapshot *storages.SongSnapshot) any {
+	if snapshot == nil {
+		return nil
+	}
+	data, _ := json.Marshal(snapshot)
+	return string(data)
+}
+
+func scanSnapshot(data []byte) (*storages.SongSnapshot, error) {
+	if data == nil {
+		return nil, nil
+	}
+	var snapshot storages.SongSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+// GetSongHistory возвращает журнал изменений песни от новых записей к старым.
+// История доступна и для окончательно удалённых песен
+func (s *PostgresStorage) GetSongHistory(ctx context.Context, songID int, page int, limit int) ([]storages.AuditEntry, error) {
+	ctx, cancel := s.withTimeout(ctx, s.timeouts.Read)
+	defer cancel()
+
+	var known bool
+	query := `SELECT EXISTS (SELECT 1 FROM songs WHERE id = $1) OR EXISTS (SELECT 1 FROM song_audit WHERE song_id = $1)`
+	if err := s.db.QueryRowContext(ctx, query, songID).Scan(&known); err != nil {
+		s.log(ctx).Printf("Ошибка при проверке песни (ID: %d): %v", songID, err)
+		return nil, translateError(err)
+	}
+	if !known {
+		return nil, storages.ErrNotFound
+	}
+
+	offset := (page - 1) * limit
+	query = `
+        SELECT id, song_id, action, actor, version, before, after, created_at
+        FROM song_audit
+        WHERE song_id = $1
+        ORDER BY id DESC
+        LIMIT $2 OFFSET $3;
+    `
+	rows, err := s.db.QueryContext(ctx, query, songID, limit, offset)
+	if err != nil {
+		s.log(ctx).Printf("Ошибка при получении истории песни (ID: %d): %v", songID, err)
+		return nil, translateError(err)
+	}
+	defer rows.Close()
+
+	entries := []storages.AuditEntry{}
+	for rows.Next() {
+		var entry storages.AuditEntry
+		var before, after []byte
+		if err := rows.Scan(&entry.ID, &entry.SongID, &entry.Action, &entry.Actor, &entry.Version, &before, &after, &entry.CreatedAt); err != nil {
+			s.log(ctx).Printf("Ошибка при сканировании записи истории песни: %v", err)
+			return nil, translateError(err)
+		}
+		if entry.Before, err = scanSnapshot(before); err != nil {
+			return nil, translateError(err)
+		}
+		if entry.After, err = scanSnapshot(after); err != nil {
+			return nil, translateError(err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+// RevertSong возвращает группу, название, дату выхода, ссылку и текст песни к состоянию
+// после изменения с номером revision. Откат сам фиксируется в журнале как новое изменение
+func (s *PostgresStorage) RevertSong(ctx context.Context, actor string, id int, revision int64, version int) (storages.Song, error) {
+	ctx, cancel := s.withTimeout(ctx, s.timeouts.Write)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		s.log(ctx).Printf("Ошибка при открытии транзакции: %v", err)
+		return storages.Song{}, translateError(err)
+	}
+	defer tx.Rollback()
+
+	if err := lockSongVersion(ctx, tx, id, version); err != nil {
+		if !errors.Is(err, storages.ErrNotFound) && !errors.Is(err, storages.ErrVersionMismatch) {
+			s.log(ctx).Printf("Ошибка при получении песни (ID: %d): %v", id, err)
+		}
+		return storages.Song{}, translateError(err)
+	}
+
+	var data []byte
+	err = tx.QueryRowContext(ctx, `SELECT after FROM song_audit WHERE id = $1 AND song_id = $2`, revision, id).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return storages.Song{}, storages.ErrNotFound
+	}
+	if err != nil {
+		s.log(ctx).Printf("Ошибка при получении ревизии песни (ID: %d, ревизия: %d): %v", id, revision, err)
+		return storages.Song{}, translateError(err)
+	}
+	target, err := scanSnapshot(data)
+	if err != nil {
+		s.log(ctx).Printf("Ошибка при разборе ревизии песни (ID: %d, ревизия: %d): %v", id, revision, err)
+		return storages.Song{}, translateError(err)
+	}
+	if target == nil {
+		return storages.Song{}, &storages.ValidationError{Field: "revision", Message: "ревизия не содержит состояния песни"}
+	}
+
+	before, err := songSnapshot(ctx, tx, id)
+	if err != nil {
+		s.log(ctx).Printf("Ошибка при получении песни (ID: %d): %v", id, err)
+		return storages.Song{}, translateError(err)
+	}
+
+	groupID, err := upsertGroup(ctx, tx, target.Group)
+	if err != nil {
+		s.log(ctx).Printf("Ошибка при создании группы (%s): %v", target.Group, err)
+		return storages.Song{}, translateError(err)
+	}
+	query := `
+        UPDATE songs
+        SET group_id = $1, name = $2, release_date = NULLIF($3, '')::date, link = NULLIF($4, ''),
+            version = version + 1, updated_at = now()
+        WHERE id = $5;
+    `
+	if err := expectAffected(tx.ExecContext(ctx, query, groupID, target.Name, target.ReleaseDate, target.Link, id)); err != nil {
+		s.log(ctx).Printf("Ошибка при откате песни (ID: %d): %v", id, err)
+		return storages.Song{}, translateError(err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM song_lyrics WHERE song_id = $1`, id); err != nil {
+		s.log(ctx).Printf("Ошибка при удалении текста песни (ID: %d): %v", id, err)
+		return storages.Song{}, translateError(err)
+	}
+	for _, verse := range target.Verses {
+		if err := insertVerse(ctx, tx, id, verse); err != nil {
+			s.log(ctx).Printf("Ошибка при добавлении куплета песни (songID: %d): %v", id, err)
+			return storages.Song{}, translateError(err)
+		}
+	}
+
+	if err := recordChange(ctx, tx, id, storages.AuditRevert, actor, before); err != nil {
+		s.log(ctx).Printf("Ошибка при записи в журнал изменений (songID: %d): %v", id, err)
+		return storages.Song{}, translateError(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		s.log(ctx).Printf("Ошибка при фиксации транзакции: %v", err)
+		return storages.Song{}, translateError(err)
+	}
+	s.log(ctx).Printf("Песня откачена к ревизии %d (ID: %d)", revision, id)
+	return s.GetSong(ctx, id)
+}
@@ -1,14 +1,16 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
+	"database/sql/driver"
 	"fmt"
-	"github.com/golang-migrate/migrate/v4"
-	"github.com/golang-migrate/migrate/v4/database/postgres"
-	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/XSAM/otelsql"
 	_ "github.com/lib/pq"
 	"github.com/sirupsen/logrus"
-	"log"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"time"
 )
 
 var db *sql.DB
@@ -22,22 +24,60 @@ type ConnectionInfo struct {
 	Password string
 }
 
+// Timeouts ограничения времени выполнения запросов по видам операций.
+// Запрос прерывается по истечении ограничения или при отмене контекста вызывающего.
+// Нулевое значение снимает ограничение
+type Timeouts struct {
+	// Чтение песен, групп, истории и ключей API
+	Read time.Duration
+	// Изменения, включая операции с очередью обогащения
+	Write time.Duration
+	// Полнотекстовый поиск по тексту песен
+	Search time.Duration
+}
+
 type PostgresStorage struct {
-	db     *sql.DB
-	logger *logrus.Logger
+	db       *sql.DB
+	logger   *logrus.Logger
+	timeouts Timeouts
+}
+
+func NewPostgresStorage(db *sql.DB, logger *logrus.Logger, timeouts Timeouts) *PostgresStorage {
+	return &PostgresStorage{db: db, logger: logger, timeouts: timeouts}
 }
 
-func NewPostgresStorage(db *sql.DB) *PostgresStorage {
-	return &PostgresStorage{db: db}
+// log возвращает запись журнала с контекстом операции, чтобы в неё попал идентификатор запроса
+func (s *PostgresStorage) log(ctx context.Context) *logrus.Entry {
+	return s.logger.WithContext(ctx)
+}
+
+// withTimeout ограничивает время выполнения операции. Если у ctx уже есть более ранний срок, действует он
+func (s *PostgresStorage) withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
 }
 
 // NewPostgresConnection создаёт новое подключение к базе данных PostgreSQL.
 // Функция принимает параметры подключения через структуру ConnectionInfo и возвращает объект *sql.DB,
 // который можно использовать для взаимодействия с базой данных.
 func NewPostgresConnection(info ConnectionInfo) (*sql.DB, error) {
-	// Формируем строку подключения для PostgreSQL с использованием параметров из ConnectionInfo
-	db, err := sql.Open("postgres", fmt.Sprintf("host=%s port=%d user=%s dbname=%s sslmode=%s password=%s",
-		info.Host, info.Port, info.Username, info.DBName, info.SSLMode, info.Password))
+	// Формируем строку подключения для PostgreSQL с использованием параметров из ConnectionInfo.
+	// Драйвер обёрнут otelsql: каждый запрос становится спаном с текстом SQL в db.statement
+	db, err := otelsql.Open("postgres", fmt.Sprintf("host=%s port=%d user=%s dbname=%s sslmode=%s password=%s",
+		info.Host, info.Port, info.Username, info.DBName, info.SSLMode, info.Password),
+		otelsql.WithAttributes(semconv.DBSystemPostgreSQL, semconv.DBNamespace(info.DBName)),
+		otelsql.WithSpanOptions(otelsql.SpanOptions{
+			OmitConnResetSession: true,
+			OmitConnPrepare:      true,
+			OmitRows:             true,
+			// Спаны создаются только внутри трассы запроса или задачи, иначе опрос очереди
+			// обогащения и миграции порождали бы поток одиночных трасс
+			SpanFilter: func(ctx context.Context, _ otelsql.Method, _ string, _ []driver.NamedValue) bool {
+				return trace.SpanContextFromContext(ctx).IsValid()
+			},
+		}))
 	if err != nil {
 		// Возвращаем ошибку, если не удалось создать подключение
 		return nil, err
@@ -45,7 +85,8 @@ func NewPostgresConnection(info ConnectionInfo) (*sql.DB, error) {
 
 	// Пингуем базу данных для проверки доступности
 	if err := db.Ping(); err != nil {
-		// Возвращаем ошибку, если не удаётся установить соединение с базой данных
+		// Закрываем пул и возвращаем ошибку, если не удаётся установить соединение с базой данных
+		db.Close()
 		return nil, err
 	}
 
@@ -53,79 +94,13 @@ func NewPostgresConnection(info ConnectionInfo) (*sql.DB, error) {
 	return db, nil
 }
 
-// Закрытие соединения с базой данных
-func CloseDB() {
+// CloseDB закрывает пул соединений с базой данных, установленный через SetDB.
+// Дожидается возврата в пул соединений, занятых текущими запросами
+func CloseDB() error {
 	if db != nil {
-		db.Close()
+		return db.Close()
 	}
-}
-
-// RunMigrations выполняет миграции базы данных, используя библиотеку migrate.
-// Функция инициализирует драйвер для миграций, загружает и применяет миграции из указанной папки.
-func RunMigrations() {
-	// Создаём миграционный драйвер для подключения к базе данных PostgreSQL.
-	// С помощью WithInstance и передаём текущую базу данных и её конфигурацию.
-	driver, err := postgres.WithInstance(db, &postgres.Config{})
-	if err != nil {
-		// Если не удалось создать миграционный драйвер, логируем ошибку и завершаем приложение.
-		log.Fatalf("Error creating migration driver: %v", err)
-	}
-
-	// Создаём мигратор, который будет управлять миграциями.
-	// Указываем путь к папке с миграциями ("file://migration") и тип базы данных ("postgres").
-	m, err := migrate.NewWithDatabaseInstance(
-		"file://migration", // Путь к папке с миграциями
-		"postgres",         // Имя базы данных
-		driver,
-	)
-	if err != nil {
-		// Если не удалось создать мигратор, логируем ошибку и завершаем приложение.
-		log.Fatalf("Error initializing migration: %v", err)
-	}
-
-	// Применяем все миграции. Если ошибок в процессе нет, применяются все миграции.
-	// Если изменений нет, игнорируем это.
-	if err := m.Up(); err != nil && err != migrate.ErrNoChange {
-		// Если при применении миграции произошла ошибка (кроме ситуации, когда изменений нет),
-		// логируем ошибку и завершаем приложение.
-		log.Fatalf("Error applying migration: %v", err)
-	}
-
-	// Если миграции успешно применены, выводим сообщение в лог.
-	log.Println("Migrations applied successfully")
-}
-
-// RollbackLastMigration откатывает последнюю применённую миграцию из базы данных.
-// Функция использует библиотеку migrate для выполнения отката миграции.
-func RollbackLastMigration() {
-	// Создаём миграционный драйвер для подключения к базе данных PostgreSQL.
-	// С помощью WithInstance и передаём текущую базу данных и её конфигурацию.
-	driver, err := postgres.WithInstance(db, &postgres.Config{})
-	if err != nil {
-		// Если не удалось создать миграционный драйвер, логируем ошибку и завершаем приложение.
-		log.Fatalf("Error creating migration driver: %v", err)
-	}
-
-	// Создаём мигратор, который будет управлять миграциями.
-	// Указываем путь к папке с миграциями ("file://migration") и тип базы данных ("postgres").
-	m, err := migrate.NewWithDatabaseInstance(
-		"file://migration", // Путь к папке с миграциями
-		"postgres",         // Имя базы данных
-		driver,
-	)
-	if err != nil {
-		// Если не удалось создать мигратор, логируем ошибку и завершаем приложение.
-		log.Fatalf("Error initializing migration: %v", err)
-	}
-
-	// Откатываем последнюю миграцию с помощью m.Steps(-1), где -1 означает откат на 1 шаг.
-	// Если не удаётся откатить миграцию, логируем ошибку и завершаем приложение.
-	if err := m.Steps(-1); err != nil && err != migrate.ErrNoChange {
-		log.Fatalf("Error rolling back migration: %v", err)
-	}
-
-	// Если откат прошел успешно, выводим сообщение в лог.
-	log.Println("Last migration rolled back successfully")
+	return nil
 }
 
 // SetDB используется для установки подключения к базе данных.
@@ -0,0 +1,312 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"songs/internal/storages"
+	"time"
+)
+
+const enrichmentJobColumns = `j.id, j.song_id, g.name, s.name, j.status, j.attempts, j.next_run_at, COALESCE(j.last_error, ''), j.created_at, j.updated_at, s.version`
+
+func scanEnrichmentJob(row interface{ Scan(...any) error }) (storages.EnrichmentJob, error) {
+	var job storages.EnrichmentJob
+	err := row.Scan(&job.ID, &job.SongID, &job.Group, &job.Song, &job.Status, &job.Attempts,
+		&job.NextRunAt, &job.LastError, &job.CreatedAt, &job.UpdatedAt, &job.SongVersion)
+	return job, err
+}
+
+// enqueueEnrichment ставит песню в очередь обогащения или перезапускает существующую задачу.
+// Статус обогащения самой песни должен выставить вызывающий код
+func enqueueEnrichment(ctx context.Context, tx *sql.Tx, songID int) error {
+	query := `
+        INSERT INTO enrichment_jobs (song_id) VALUES ($1)
+        ON CONFLICT (song_id) DO UPDATE
+        SET status = 'pending', attempts = 0, next_run_at = now(), locked_until = NULL,
+            last_error = NULL, updated_at = now();
+    `
+	_, err := tx.ExecContext(ctx, query, songID)
+	return err
+}
+
+func (s *PostgresStorage) GetEnrichmentJob(ctx context.Context, songID int) (storages.EnrichmentJob, error) {
+	ctx, cancel := s.withTimeout(ctx, s.timeouts.Read)
+	defer cancel()
+
+	query := `
+        SELECT ` + enrichmentJobColumns + `
+        FROM enrichment_jobs j
+        JOIN songs s ON s.id = j.song_id
+        JOIN groups g ON g.id = s.group_id
+        WHERE j.song_id = $1;
+    `
+	job, err := scanEnrichmentJob(s.db.QueryRowContext(ctx, query, songID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return job, storages.ErrNotFound
+	}
+	if err != nil {
+		s.log(ctx).Printf("Ошибка при получении задачи обогащения (songID: %d): %v", songID, err)
+		return job, translateError(err)
+	}
+	return job, nil
+}
+
+// RetryEnrichment повторно ставит песню в очередь обогащения со сброшенным счётчиком попыток
+func (s *PostgresStorage) RetryEnrichment(ctx context.Context, actor string, songID int) (storages.EnrichmentJob, error) {
+	ctx, cancel := s.withTimeout(ctx, s.timeouts.Write)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		s.log(ctx).Printf("Ошибка при открытии транзакции: %v", err)
+		return storages.EnrichmentJob{}, translateError(err)
+	}
+	defer tx.Rollback()
+
+	var exists bool
+	err = tx.QueryRowContext(ctx, `SELECT true FROM songs WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`, songID).Scan(&exists)
+	if errors.Is(err, sql.ErrNoRows) {
+		return storages.EnrichmentJob{}, storages.ErrNotFound
+	}
+	if err != nil {
+		s.log(ctx).Printf("Ошибка при получении песни (ID: %d): %v", songID, err)
+		return storages.EnrichmentJob{}, translateError(err)
+	}
+	before, err := songSnapshot(ctx, tx, songID)
+	if err != nil {
+		s.log(ctx).Printf("Ошибка при получении песни (ID: %d): %v", songID, err)
+		return storages.EnrichmentJob{}, translateError(err)
+	}
+
+	if err := enqueueEnrichment(ctx, tx, songID); err != nil {
+		s.log(ctx).Printf("Ошибка при постановке песни в очередь обогащения (ID: %d): %v", songID, err)
+		return storages.EnrichmentJob{}, translateError(err)
+	}
+	query := `UPDATE songs SET enrichment_status = $1, version = version + 1, updated_at = now() WHERE id = $2`
+	if _, err := tx.ExecContext(ctx, query, storages.EnrichmentPending, songID); err != nil {
+		s.log(ctx).Printf("Ошибка при обновлении статуса песни (ID: %d): %v", songID, err)
+		return storages.EnrichmentJob{}, translateError(err)
+	}
+	if err := recordChange(ctx, tx, songID, storages.AuditEnrichment, actor, before); err != nil {
+		s.log(ctx).Printf("Ошибка при записи в журнал изменений (songID: %d): %v", songID, err)
+		return storages.EnrichmentJob{}, translateError(err)
+	}
+	if err := tx.Commit(); err != nil {
+		s.log(ctx).Printf("Ошибка при фиксации транзакции: %v", err)
+		return storages.EnrichmentJob{}, translateError(err)
+	}
+
+	s.log(ctx).Printf("Песня поставлена в очередь обогащения повторно (ID: %d)", songID)
+	return s.GetEnrichmentJob(ctx, songID)
+}
+
+// ClaimEnrichmentJob захватывает задачу, время которой подошло, либо задачу,
+// аренда которой истекла (например, после падения воркера)
+func (s *PostgresStorage) ClaimEnrichmentJob(ctx context.Context, lease time.Duration) (storages.EnrichmentJob, error) {
+	ctx, cancel := s.withTimeout(ctx, s.timeouts.Write)
+	defer cancel()
+
+	query := `
+        WITH claimed AS (
+            UPDATE enrichment_jobs
+            SET status = 'running', attempts = attempts + 1,
+                locked_until = now() + $1 * interval '1 millisecond', updated_at = now()
+            WHERE id = (
+                SELECT id FROM enrichment_jobs
+                WHERE ((status = 'pending' AND next_run_at <= now())
+                   OR (status = 'running' AND locked_until < now()))
+                  AND song_id IN (SELECT id FROM songs WHERE deleted_at IS NULL)
+                ORDER BY next_run_at
+                LIMIT 1
+                FOR UPDATE SKIP LOCKED
+            )
+            RETURNING *
+        )
+        SELECT ` + enrichmentJobColumns + `
+        FROM claimed j
+        JOIN songs s ON s.id = j.song_id
+        JOIN groups g ON g.id = s.group_id;
+    `
+	job, err := scanEnrichmentJob(s.db.QueryRowContext(ctx, query, lease.Milliseconds()))
+	if errors.Is(err, sql.ErrNoRows) {
+		return job, storages.ErrNotFound
+	}
+	if err != nil {
+		s.log(ctx).Printf("Ошибка при захвате задачи обогащения: %v", err)
+		return job, translateError(err)
+	}
+	return job, nil
+}
+
+// lockClaimedJob блокирует песню и задачу и проверяет, что задача всё ещё принадлежит воркеру:
+// песня не удалена в корзину, а задачу не перезапустили и не захватили повторно после истечения аренды.
+// Возвращает текущую версию песни или ErrJobReleased
+func lockClaimedJob(ctx context.Context, tx *sql.Tx, job storages.EnrichmentJob) (int, error) {
+	var version int
+	err := tx.QueryRowContext(ctx, `SELECT version FROM songs WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`, job.SongID).Scan(&version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, storages.ErrJobReleased
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	query := `SELECT true FROM enrichment_jobs WHERE id = $1 AND status = 'running' AND attempts = $2 FOR UPDATE`
+	var claimed bool
+	err = tx.QueryRowContext(ctx, query, job.ID, job.Attempts).Scan(&claimed)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, storages.ErrJobReleased
+	}
+	if err != nil {
+		return 0, err
+	}
+	return version, nil
+}
+
+// CompleteEnrichmentJob заполняет дату выхода, ссылку и текст песни и закрывает задачу в одной транзакции.
+// Если песню изменили после захвата задачи, заполняются только пустые поля, чтобы не затереть правки редактора.
+// Если задача больше не принадлежит воркеру, результат отбрасывается с ошибкой ErrJobReleased
+func (s *PostgresStorage) CompleteEnrichmentJob(ctx context.Context, job storages.EnrichmentJob, detail storages.SongDetail) error {
+	ctx, cancel := s.withTimeout(ctx, s.timeouts.Write)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		s.log(ctx).Printf("Ошибка при открытии транзакции: %v", err)
+		return translateError(err)
+	}
+	defer tx.Rollback()
+
+	version, err := lockClaimedJob(ctx, tx, job)
+	if errors.Is(err, storages.ErrJobReleased) {
+		return err
+	}
+	if err != nil {
+		s.log(ctx).Printf("Ошибка при проверке задачи обогащения (ID: %d): %v", job.ID, err)
+		return translateError(err)
+	}
+	changed := version != job.SongVersion
+
+	before, err := songSnapshot(ctx, tx, job.SongID)
+	if err != nil {
+		s.log(ctx).Printf("Ошибка при получении песни (ID: %d): %v", job.SongID, err)
+		return translateError(err)
+	}
+
+	query := `
+        UPDATE songs
+        SET release_date = NULLIF($1, '')::date, link = NULLIF($2, ''), enrichment_status = $3,
+            version = version + 1, updated_at = now()
+        WHERE id = $4;
+    `
+	if changed {
+		query = `
+        UPDATE songs
+        SET release_date = COALESCE(release_date, NULLIF($1, '')::date), link = COALESCE(NULLIF(link, ''), NULLIF($2, '')),
+            enrichment_status = $3, version = version + 1, updated_at = now()
+        WHERE id = $4;
+    `
+	}
+	if _, err := tx.ExecContext(ctx, query, detail.ReleaseDate, detail.Link, storages.EnrichmentDone, job.SongID); err != nil {
+		s.log(ctx).Printf("Ошибка при обновлении песни (ID: %d): %v", job.SongID, err)
+		return translateError(err)
+	}
+
+	// Текст, который редактор изменил после захвата задачи, не заменяется
+	replaceLyrics := !changed || len(before.Verses) == 0
+	if replaceLyrics {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM song_lyrics WHERE song_id = $1`, job.SongID); err != nil {
+			s.log(ctx).Printf("Ошибка при удалении текста песни (ID: %d): %v", job.SongID, err)
+			return translateError(err)
+		}
+		for _, verse := range detail.Verses() {
+			if err := insertVerse(ctx, tx, job.SongID, verse); err != nil {
+				s.log(ctx).Printf("Ошибка при добавлении куплета песни (songID: %d): %v", job.SongID, err)
+				return translateError(err)
+			}
+		}
+	}
+	if err := recordChange(ctx, tx, job.SongID, storages.AuditEnrichment, storages.ActorEnrichment, before); err != nil {
+		s.log(ctx).Printf("Ошибка при записи в журнал изменений (songID: %d): %v", job.SongID, err)
+		return translateError(err)
+	}
+
+	query = `
+        UPDATE enrichment_jobs
+        SET status = 'done', locked_until = NULL, last_error = NULL, updated_at = now()
+        WHERE id = $1;
+    `
+	if _, err := tx.ExecContext(ctx, query, job.ID); err != nil {
+		s.log(ctx).Printf("Ошибка при закрытии задачи обогащения (ID: %d): %v", job.ID, err)
+		return translateError(err)
+	}
+
+	return translateError(tx.Commit())
+}
+
+func (s *PostgresStorage) RescheduleEnrichmentJob(ctx context.Context, job storages.EnrichmentJob, reason string, runAt time.Time) error {
+	ctx, cancel := s.withTimeout(ctx, s.timeouts.Write)
+	defer cancel()
+
+	query := `
+        UPDATE enrichment_jobs
+        SET status = 'pending', next_run_at = $1, locked_until = NULL, last_error = $2, updated_at = now()
+        WHERE id = $3 AND status = 'running' AND attempts = $4;
+    `
+	err := expectAffected(s.db.ExecContext(ctx, query, runAt, reason, job.ID, job.Attempts))
+	if errors.Is(err, storages.ErrNotFound) {
+		// Задачу перезапустили или захватил другой воркер, её судьбу решают они
+		return storages.ErrJobReleased
+	}
+	if err != nil {
+		s.log(ctx).Printf("Ошибка при переносе задачи обогащения (ID: %d): %v", job.ID, err)
+		return translateError(err)
+	}
+	return nil
+}
+
+func (s *PostgresStorage) DeadLetterEnrichmentJob(ctx context.Context, job storages.EnrichmentJob, reason string) error {
+	ctx, cancel := s.withTimeout(ctx, s.timeouts.Write)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		s.log(ctx).Printf("Ошибка при открытии транзакции: %v", err)
+		return translateError(err)
+	}
+	defer tx.Rollback()
+
+	if _, err := lockClaimedJob(ctx, tx, job); errors.Is(err, storages.ErrJobReleased) {
+		return err
+	} else if err != nil {
+		s.log(ctx).Printf("Ошибка при проверке задачи обогащения (ID: %d): %v", job.ID, err)
+		return translateError(err)
+	}
+
+	before, err := songSnapshot(ctx, tx, job.SongID)
+	if err != nil {
+		s.log(ctx).Printf("Ошибка при получении песни (ID: %d): %v", job.SongID, err)
+		return translateError(err)
+	}
+
+	query := `
+        UPDATE enrichment_jobs
+        SET status = 'dead', locked_until = NULL, last_error = $1, updated_at = now()
+        WHERE id = $2;
+    `
+	if _, err := tx.ExecContext(ctx, query, reason, job.ID); err != nil {
+		s.log(ctx).Printf("Ошибка при закрытии задачи обогащения (ID: %d): %v", job.ID, err)
+		return translateError(err)
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE songs SET enrichment_status = $1, version = version + 1, updated_at = now() WHERE id = $2`, storages.EnrichmentFailed, job.SongID); err != nil {
+		s.log(ctx).Printf("Ошибка при обновлении статуса песни (ID: %d): %v", job.SongID, err)
+		return translateError(err)
+	}
+	if err := recordChange(ctx, tx, job.SongID, storages.AuditEnrichment, storages.ActorEnrichment, before); err != nil {
+		s.log(ctx).Printf("Ошибка при записи в журнал изменений (songID: %d): %v", job.SongID, err)
+		return translateError(err)
+	}
+
+	return translateError(tx.Commit())
+}
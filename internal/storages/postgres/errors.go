@@ -0,0 +1,78 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/lib/pq"
+	"songs/internal/storages"
+)
+
+// Коды ошибок Postgres, которые переводятся в ошибки предметной области
+const (
+	codeUniqueViolation     = "23505"
+	codeForeignKeyViolation = "23503"
+	codeNotNullViolation    = "23502"
+	codeCheckViolation      = "23514"
+	// Запрос отменён по statement_timeout или по отмене контекста
+	codeQueryCanceled = "57014"
+	// Класс ошибок данных: неверный формат, переполнение, слишком длинная строка и т.п.
+	classDataException = "22"
+)
+
+// translateError переводит ошибку Postgres в ошибку предметной области из пакета storages,
+// чтобы обработчики могли выбрать код ответа, не разбирая ошибки драйвера.
+// Прочие ошибки возвращаются без изменений
+func translateError(err error) error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("%w: %w", storages.ErrTimeout, err)
+	}
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return err
+	}
+
+	switch {
+	case pqErr.Code == codeUniqueViolation:
+		return &storages.ConstraintError{Err: storages.ErrConflict, Constraint: pqErr.Constraint}
+	case pqErr.Code == codeForeignKeyViolation:
+		// Вставка или изменение ссылки на отсутствующую строку. Удаление строки, на которую ссылаются,
+		// даёт тот же код, его переводит translateDeleteError
+		return &storages.ConstraintError{Err: storages.ErrMissingReference, Constraint: pqErr.Constraint}
+	case pqErr.Code == codeNotNullViolation:
+		return &storages.ValidationError{Field: pqErr.Column, Message: "значение обязательно"}
+	case pqErr.Code == codeCheckViolation, pqErr.Code.Class() == classDataException:
+		return &storages.ValidationError{Field: pqErr.Column, Message: "недопустимое значение"}
+	case pqErr.Code == codeQueryCanceled:
+		return fmt.Errorf("%w: %w", storages.ErrTimeout, err)
+	}
+	return err
+}
+
+// translateDeleteError переводит ошибку запроса DELETE. Нарушение внешнего ключа при удалении означает,
+// что на строку ещё ссылаются. Отличить этот случай от вставки по тексту ошибки нельзя:
+// Postgres переводит DETAIL на язык сервера, а таблица и ограничение в обоих случаях те же
+func translateDeleteError(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == codeForeignKeyViolation {
+		return &storages.ConstraintError{Err: storages.ErrReferenced, Constraint: pqErr.Constraint}
+	}
+	return translateError(err)
+}
+
+// expectAffected проверяет, что запрос изменил хотя бы одну строку, иначе возвращает ErrNotFound.
+// Принимает результат Exec целиком, чтобы вызов оставался однострочным
+func expectAffected(res sql.Result, err error) error {
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return storages.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,246 @@
+package postgres
+
+import (
+	"fmt"
+	"songs/internal/storages"
+	"strings"
+)
+
+// sortColumn выражение SQL для поля сортировки и тип, к которому приводится значение курсора.
+// Выражения не возвращают NULL, иначе сравнение по ключу (keyset) теряло бы строки
+type sortColumn struct {
+	expr string
+	cast string
+}
+
+// songSortColumns сопоставляет полям сортировки выражения SQL.
+// Имена столбцов никогда не берутся из запроса напрямую, только из этого списка.
+// Песни без даты выхода считаются самыми новыми, без ссылки - с пустой ссылкой
+var songSortColumns = map[string]sortColumn{
+	"id":                {"s.id", "int"},
+	"group_id":          {"s.group_id", "int"},
+	"group":             {"g.name", "text"},
+	"song":              {"s.name", "text"},
+	"release_date":      {"COALESCE(s.release_date, 'infinity'::date)", "date"},
+	"link":              {"COALESCE(s.link, '')", "text"},
+	"enrichment_status": {"s.enrichment_status", "text"},
+	"version":           {"s.version", "int"},
+	"updated_at":        {"s.updated_at", "timestamptz"},
+	"deleted_at":        {"COALESCE(s.deleted_at, 'infinity'::timestamptz)", "timestamptz"},
+}
+
+// sortKey ключ сортировки с направлением
+type sortKey struct {
+	sortColumn
+	desc bool
+}
+
+// queryBuilder накапливает условия WHERE и соответствующие им параметры запроса
+type queryBuilder struct {
+	conditions []string
+	args       []interface{}
+}
+
+// arg добавляет параметр и возвращает его плейсхолдер
+func (b *queryBuilder) arg(value interface{}) string {
+	b.args = append(b.args, value)
+	return fmt.Sprintf("$%d", len(b.args))
+}
+
+func (b *queryBuilder) where(condition string) {
+	b.conditions = append(b.conditions, condition)
+}
+
+func (b *queryBuilder) whereClause() string {
+	if len(b.conditions) == 0 {
+		return ""
+	}
+	return "WHERE " + strings.Join(b.conditions, " AND ")
+}
+
+// text добавляет строковый фильтр без учёта регистра в выбранном режиме сопоставления
+func (b *queryBuilder) text(column string, filter storages.TextFilter) {
+	if filter.Value == "" {
+		return
+	}
+	pattern := escapeLike(filter.Value)
+	switch filter.Match {
+	case storages.MatchExact:
+		b.where(fmt.Sprintf("LOWER(%s) = LOWER(%s)", column, b.arg(filter.Value)))
+		return
+	case storages.MatchPrefix:
+		pattern += "%"
+	default:
+		pattern = "%" + pattern + "%"
+	}
+	b.where(fmt.Sprintf("%s ILIKE %s", column, b.arg(pattern)))
+}
+
+// escapeLike экранирует спецсимволы шаблона LIKE, чтобы они искались буквально
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
+
+// songFilterQuery строит условия отбора песен по фильтру
+func songFilterQuery(filter storages.SongFilter) *queryBuilder {
+	b := &queryBuilder{}
+	if filter.Trashed {
+		b.where("s.deleted_at IS NOT NULL")
+	} else {
+		b.where("s.deleted_at IS NULL")
+	}
+	if filter.ID != 0 {
+		b.where("s.id = " + b.arg(filter.ID))
+	}
+	if filter.GroupID != 0 {
+		b.where("s.group_id = " + b.arg(filter.GroupID))
+	}
+	b.text("g.name", filter.Group)
+	b.text("s.name", filter.Song)
+	b.text("s.link", filter.Link)
+	if filter.ReleaseDate != "" {
+		b.where("s.release_date = " + b.arg(filter.ReleaseDate) + "::date")
+	}
+	if filter.ReleaseDateFrom != "" {
+		b.where("s.release_date >= " + b.arg(filter.ReleaseDateFrom) + "::date")
+	}
+	if filter.ReleaseDateTo != "" {
+		b.where("s.release_date <= " + b.arg(filter.ReleaseDateTo) + "::date")
+	}
+	if filter.EnrichmentStatus != "" {
+		b.where("s.enrichment_status = " + b.arg(filter.EnrichmentStatus))
+	}
+	if filter.Version != 0 {
+		b.where("s.version = " + b.arg(filter.Version))
+	}
+	if !filter.UpdatedFrom.IsZero() {
+		b.where("s.updated_at >= " + b.arg(filter.UpdatedFrom))
+	}
+	if !filter.UpdatedTo.IsZero() {
+		b.where("s.updated_at <= " + b.arg(filter.UpdatedTo))
+	}
+	return b
+}
+
+// songSortKeys строит ключи сортировки по белому списку полей.
+// id в конце делает порядок детерминированным и служит ключом курсора
+func songSortKeys(sort []storages.SortField) ([]sortKey, error) {
+	var keys []sortKey
+	hasID := false
+	for _, field := range sort {
+		column, ok := songSortColumns[field.Field]
+		if !ok {
+			return nil, fmt.Errorf("сортировка по полю %q не поддерживается", field.Field)
+		}
+		keys = append(keys, sortKey{sortColumn: column, desc: field.Desc})
+		if field.Field == "id" {
+			hasID = true
+			break
+		}
+	}
+	if !hasID {
+		keys = append(keys, sortKey{sortColumn: songSortColumns["id"]})
+	}
+	return keys, nil
+}
+
+// sortSignature возвращает каноническое представление сортировки для привязки к нему курсора
+func sortSignature(sort []storages.SortField) string {
+	parts := make([]string, 0, len(sort))
+	for _, field := range sort {
+		if field.Desc {
+			parts = append(parts, "-"+field.Field)
+		} else {
+			parts = append(parts, field.Field)
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// orderBy строит ORDER BY по ключам. При движении назад порядок инвертируется
+func orderBy(keys []sortKey, backward bool) string {
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		direction := "ASC"
+		if key.desc != backward {
+			direction = "DESC"
+		}
+		parts = append(parts, key.expr+" "+direction)
+	}
+	return "ORDER BY " + strings.Join(parts, ", ")
+}
+
+// keyColumns возвращает выражения ключей, приведённые к тексту, для сохранения в курсоре
+func keyColumns(keys []sortKey) string {
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		parts = append(parts, key.expr+"::text")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// keyset добавляет условие "строго после курсора" в порядке сортировки (или "строго до" при движении назад):
+// (k1 > v1) OR (k1 = v1 AND k2 > v2) OR ...
+func (b *queryBuilder) keyset(keys []sortKey, values []string, backward bool) {
+	var or []string
+	for i, key := range keys {
+		var and []string
+		for j := 0; j < i; j++ {
+			and = append(and, fmt.Sprintf("%s = %s::%s", keys[j].expr, b.arg(values[j]), keys[j].cast))
+		}
+		op := ">"
+		if key.desc != backward {
+			op = "<"
+		}
+		and = append(and, fmt.Sprintf("%s %s %s::%s", key.expr, op, b.arg(values[i]), key.cast))
+		or = append(or, "("+strings.Join(and, " AND ")+")")
+	}
+	b.where("(" + strings.Join(or, " OR ") + ")")
+}
+
+// pageLinks заполняет has_more и курсоры соседних страниц.
+// keys содержит ключи не более чем limit элементов в порядке вывода, extra сообщает,
+// что в направлении движения есть ещё элементы
+func pageLinks(page *storages.PageInfo, keys [][]string, extra bool, cursor *storages.Cursor, signature string) {
+	first := func() string {
+		return storages.Cursor{Keys: keys[0], Backward: true, Sort: signature}.Encode()
+	}
+	last := func() string {
+		return storages.Cursor{Keys: keys[len(keys)-1], Sort: signature}.Encode()
+	}
+
+	switch {
+	case cursor == nil:
+		page.HasMore = extra
+		if extra {
+			page.Next = last()
+		}
+		if page.Page > 1 && len(keys) > 0 {
+			page.Prev = first()
+		}
+	case !cursor.Backward:
+		page.HasMore = extra
+		if extra {
+			page.Next = last()
+		}
+		if len(keys) > 0 {
+			page.Prev = first()
+		}
+	default:
+		// Назад переходят с более поздней страницы, поэтому после текущей элементы точно есть
+		page.HasMore = true
+		if len(keys) > 0 {
+			page.Next = last()
+		}
+		if extra {
+			page.Prev = first()
+		}
+	}
+}
+
+// reverse разворачивает срез на месте
+func reverse[T any](items []T) {
+	for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+		items[i], items[j] = items[j], items[i]
+	}
+}
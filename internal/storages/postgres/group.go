@@ -0,0 +1,188 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"songs/internal/storages"
+)
+
+func (s *PostgresStorage) GetGroups(ctx context.Context, name string, page int, limit int) ([]storages.Group, error) {
+	ctx, cancel := s.withTimeout(ctx, s.timeouts.Read)
+	defer cancel()
+
+	offset := (page - 1) * limit
+	query := `
+        SELECT id, name
+        FROM groups
+        WHERE name ILIKE $1
+        ORDER BY name, id
+        LIMIT $2 OFFSET $3;
+    `
+	// % и _ в названии ищутся как обычные символы
+	rows, err := s.db.QueryContext(ctx, query, "%"+escapeLike(name)+"%", limit, offset)
+	if err != nil {
+		s.log(ctx).Printf("Ошибка при получении групп: %v", err)
+		return nil, translateError(err)
+	}
+	defer rows.Close()
+
+	groups := []storages.Group{}
+	for rows.Next() {
+		var group storages.Group
+		if err := rows.Scan(&group.ID, &group.Name); err != nil {
+			s.log(ctx).Printf("Ошибка при сканировании результата: %v", err)
+			return nil, translateError(err)
+		}
+		groups = append(groups, group)
+	}
+	return groups, rows.Err()
+}
+
+func (s *PostgresStorage) GetGroup(ctx context.Context, id int) (storages.Group, error) {
+	ctx, cancel := s.withTimeout(ctx, s.timeouts.Read)
+	defer cancel()
+
+	var group storages.Group
+	query := `SELECT id, name FROM groups WHERE id = $1`
+	err := s.db.QueryRowContext(ctx, query, id).Scan(&group.ID, &group.Name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return group, storages.ErrNotFound
+	}
+	if err != nil {
+		s.log(ctx).Printf("Ошибка при получении группы (ID: %d): %v", id, err)
+		return group, translateError(err)
+	}
+	return group, nil
+}
+
+func (s *PostgresStorage) AddGroup(ctx context.Context, group storages.Group) (int, error) {
+	ctx, cancel := s.withTimeout(ctx, s.timeouts.Write)
+	defer cancel()
+
+	var id int
+	query := `INSERT INTO groups (name) VALUES ($1) RETURNING id`
+	if err := s.db.QueryRowContext(ctx, query, group.Name).Scan(&id); err != nil {
+		s.log(ctx).Printf("Ошибка при добавлении группы (%s): %v", group.Name, err)
+		return 0, translateError(err)
+	}
+	s.log(ctx).Printf("Группа успешно добавлена (ID: %d, название: %s)", id, group.Name)
+	return id, nil
+}
+
+// UpdateGroup переименовывает группу. Имя группы входит в представление её песен,
+// поэтому в той же транзакции версии песен увеличиваются, чтобы их ETag сменились,
+// а в журнал изменений каждой песни записывается переименование от имени actor
+func (s *PostgresStorage) UpdateGroup(ctx context.Context, actor string, id int, group storages.Group) error {
+	ctx, cancel := s.withTimeout(ctx, s.timeouts.Write)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		s.log(ctx).Printf("Ошибка при открытии транзакции: %v", err)
+		return translateError(err)
+	}
+	defer tx.Rollback()
+
+	// Блокировка группы не даёт добавить в неё песню до конца транзакции,
+	// иначе версия новой песни увеличилась бы без записи в журнале
+	var locked int
+	err = tx.QueryRowContext(ctx, `SELECT id FROM groups WHERE id = $1 FOR UPDATE`, id).Scan(&locked)
+	if errors.Is(err, sql.ErrNoRows) {
+		return storages.ErrNotFound
+	}
+	if err != nil {
+		s.log(ctx).Printf("Ошибка при блокировке группы (ID: %d): %v", id, err)
+		return translateError(err)
+	}
+
+	befores, err := s.groupSongSnapshots(ctx, tx, id)
+	if err != nil {
+		s.log(ctx).Printf("Ошибка при чтении песен группы (ID: %d): %v", id, err)
+		return translateError(err)
+	}
+
+	query := `UPDATE groups SET name = $1 WHERE id = $2`
+	if _, err := tx.ExecContext(ctx, query, group.Name, id); err != nil {
+		s.log(ctx).Printf("Ошибка при обновлении группы (ID: %d): %v", id, err)
+		return translateError(err)
+	}
+	query = `UPDATE songs SET version = version + 1, updated_at = now() WHERE group_id = $1`
+	if _, err := tx.ExecContext(ctx, query, id); err != nil {
+		s.log(ctx).Printf("Ошибка при обновлении версий песен группы (ID: %d): %v", id, err)
+		return translateError(err)
+	}
+	for _, before := range befores {
+		if err := recordChange(ctx, tx, before.ID, storages.AuditGroupRename, actor, before); err != nil {
+			s.log(ctx).Printf("Ошибка при записи в журнал изменений (songID: %d): %v", before.ID, err)
+			return translateError(err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		s.log(ctx).Printf("Ошибка при фиксации транзакции: %v", err)
+		return translateError(err)
+	}
+	s.log(ctx).Printf("Успешно обновлена группа (ID: %d, песен: %d)", id, len(befores))
+	return nil
+}
+
+// groupSongSnapshots блокирует песни группы, включая песни из корзины, и возвращает их снимки
+func (s *PostgresStorage) groupSongSnapshots(ctx context.Context, tx *sql.Tx, groupID int) ([]*storages.SongSnapshot, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT id FROM songs WHERE group_id = $1 ORDER BY id FOR UPDATE`, groupID)
+	if err != nil {
+		return nil, err
+	}
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	snapshots := make([]*storages.SongSnapshot, 0, len(ids))
+	for _, id := range ids {
+		snapshot, err := songSnapshot(ctx, tx, id)
+		if err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	return snapshots, nil
+}
+
+func (s *PostgresStorage) DeleteGroup(ctx context.Context, id int) error {
+	ctx, cancel := s.withTimeout(ctx, s.timeouts.Write)
+	defer cancel()
+
+	query := `DELETE FROM groups WHERE id = $1`
+	res, err := s.db.ExecContext(ctx, query, id)
+	if err != nil {
+		s.log(ctx).Printf("Ошибка при удалении группы (ID: %d): %v", id, err)
+		return translateDeleteError(err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return storages.ErrNotFound
+	}
+	s.log(ctx).Printf("Успешно удалена группа (ID: %d)", id)
+	return nil
+}
+
+// upsertGroup возвращает ID группы с указанным названием, создавая её при отсутствии.
+// Выполняется в рамках переданной транзакции.
+func upsertGroup(ctx context.Context, tx *sql.Tx, name string) (int, error) {
+	var id int
+	query := `
+        INSERT INTO groups (name) VALUES ($1)
+        ON CONFLICT (name) DO UPDATE SET name = EXCLUDED.name
+        RETURNING id;
+    `
+	err := tx.QueryRowContext(ctx, query, name).Scan(&id)
+	return id, err
+}
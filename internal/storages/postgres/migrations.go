@@ -0,0 +1,181 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"log"
+	"os"
+	"songs/migration"
+)
+
+// Ошибки мигратора, которые вызывающему коду нужно отличать от сбоев
+var (
+	// ErrNoChange схема уже находится в запрошенной версии
+	ErrNoChange = migrate.ErrNoChange
+	// ErrNilVersion ни одна миграция ещё не применена
+	ErrNilVersion = migrate.ErrNilVersion
+)
+
+// openMigrations открывает миграции, встроенные в бинарный файл
+func openMigrations() (source.Driver, error) {
+	src, err := iofs.New(migration.FS, ".")
+	if err != nil {
+		return nil, fmt.Errorf("error opening migration source: %w", err)
+	}
+	return src, nil
+}
+
+// Migrator управляет версией схемы базы по встроенным миграциям.
+// Одновременно с другими экземплярами схему не меняет: на время операции берётся advisory lock
+type Migrator struct {
+	m *migrate.Migrate
+}
+
+// NewMigrator создаёт мигратор на отдельном соединении из пула db.
+// Close возвращает соединение в пул, сам пул остаётся открытым
+func NewMigrator(db *sql.DB) (*Migrator, error) {
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error creating migration driver: %w", err)
+	}
+	// WithInstance закрыл бы вместе с мигратором и весь пул, поэтому драйвер получает только соединение
+	driver, err := postgres.WithConnection(ctx, conn, &postgres.Config{})
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("error creating migration driver: %w", err)
+	}
+
+	src, err := openMigrations()
+	if err != nil {
+		driver.Close()
+		return nil, err
+	}
+	m, err := migrate.NewWithInstance("iofs", src, "postgres", driver)
+	if err != nil {
+		src.Close()
+		driver.Close()
+		return nil, fmt.Errorf("error initializing migration: %w", err)
+	}
+	m.Log = migrateLogger{}
+	return &Migrator{m: m}, nil
+}
+
+// Up применяет все ещё не применённые миграции
+func (m *Migrator) Up() error {
+	return m.m.Up()
+}
+
+// Down откатывает n последних применённых миграций
+func (m *Migrator) Down(n int) error {
+	if n < 1 {
+		return fmt.Errorf("количество откатываемых миграций должно быть положительным: %d", n)
+	}
+	return m.m.Steps(-n)
+}
+
+// Goto применяет или откатывает миграции до версии version
+func (m *Migrator) Goto(version uint) error {
+	return m.m.Migrate(version)
+}
+
+// Version возвращает текущую версию схемы и признак незавершённой миграции
+func (m *Migrator) Version() (uint, bool, error) {
+	return m.m.Version()
+}
+
+// Force записывает версию схемы без выполнения миграций и снимает признак незавершённой миграции.
+// Используется после ручного исправления базы, когда миграция упала на середине.
+// Версия -1 означает, что ни одна миграция не применена
+func (m *Migrator) Force(version int) error {
+	return m.m.Force(version)
+}
+
+// Close освобождает соединение мигратора
+func (m *Migrator) Close() error {
+	srcErr, dbErr := m.m.Close()
+	return errors.Join(srcErr, dbErr)
+}
+
+// migrateLogger выводит ход миграций в стандартный журнал
+type migrateLogger struct{}
+
+func (migrateLogger) Printf(format string, v ...interface{}) {
+	log.Printf("migrate: "+format, v...)
+}
+
+func (migrateLogger) Verbose() bool {
+	return false
+}
+
+// RunMigrations применяет все миграции к базе, установленной через SetDB.
+// Ошибки возвращаются вызывающему коду, процесс не завершается.
+func RunMigrations() error {
+	m, err := NewMigrator(db)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	// Если изменений нет, игнорируем это.
+	if err := m.Up(); err != nil && !errors.Is(err, ErrNoChange) {
+		return fmt.Errorf("error applying migration: %w", err)
+	}
+
+	// Если миграции успешно применены, выводим сообщение в лог.
+	log.Println("Migrations applied successfully")
+	return nil
+}
+
+// LatestMigration возвращает номер последней встроенной миграции, то есть версию схемы,
+// которую ожидает эта сборка приложения
+func LatestMigration() (uint, error) {
+	src, err := openMigrations()
+	if err != nil {
+		return 0, err
+	}
+	defer src.Close()
+
+	version, err := src.First()
+	if err != nil {
+		return 0, fmt.Errorf("error reading migration source: %w", err)
+	}
+	for {
+		next, err := src.Next(version)
+		if errors.Is(err, os.ErrNotExist) {
+			return version, nil
+		}
+		if err != nil {
+			return 0, fmt.Errorf("error reading migration source: %w", err)
+		}
+		version = next
+	}
+}
+
+// CheckMigrations сверяет версию схемы в базе с ожидаемой версией latest.
+// Версия читается из таблицы schema_migrations напрямую, без блокировки мигратора,
+// поэтому проверку можно выполнять часто
+func CheckMigrations(ctx context.Context, db *sql.DB, latest uint) error {
+	var version uint
+	var dirty bool
+	err := db.QueryRowContext(ctx, `SELECT version, dirty FROM schema_migrations LIMIT 1`).Scan(&version, &dirty)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("миграции не применены, ожидается версия %d", latest)
+	}
+	if err != nil {
+		return translateError(err)
+	}
+	if dirty {
+		return fmt.Errorf("миграция %d применена не полностью", version)
+	}
+	if version != latest {
+		return fmt.Errorf("версия схемы %d, ожидается %d", version, latest)
+	}
+	return nil
+}
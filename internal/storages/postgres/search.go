@@ -0,0 +1,119 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"songs/internal/storages"
+	"strings"
+	"unicode/utf8"
+)
+
+// Границы совпадений, которые расставляет ts_headline. Это символы из области для частного
+// использования: в тексте песен их не ожидается, а в ответе они заменяются на <mark></mark>
+const (
+	highlightStart = "\uE000"
+	highlightStop  = "\uE001"
+)
+
+// SearchLyrics ищет песни по тексту с помощью полнотекстового поиска Postgres.
+// Запрос разбирается websearch_to_tsquery, поэтому поддерживаются фразы в кавычках, OR и исключения через "-".
+// Песни упорядочены по суммарной релевантности совпавших строк.
+func (s *PostgresStorage) SearchLyrics(ctx context.Context, phrase string, language string, page int, limit int) ([]storages.SearchResult, error) {
+	ctx, cancel := s.withTimeout(ctx, s.timeouts.Search)
+	defer cancel()
+
+	// Конфигурация подставляется в запрос литералом, иначе Postgres не использует индекс по выражению.
+	// Поэтому допускаются только языки из белого списка
+	if !storages.IsSearchLanguage(language) {
+		return nil, fmt.Errorf("неподдерживаемый язык поиска: %s", language)
+	}
+	cfg := "'" + language + "'"
+	tsv := "to_tsvector(" + cfg + ", l.lyrics_line)"
+
+	offset := (page - 1) * limit
+	query := `
+        WITH q AS (
+            SELECT websearch_to_tsquery(` + cfg + `, $1) AS query
+        ),
+        hits AS (
+            SELECT l.song_id, l.verse_number, l.line_number, l.lyrics_line,
+                   ts_rank(` + tsv + `, q.query) AS rank
+            FROM song_lyrics l
+            JOIN songs s ON s.id = l.song_id AND s.deleted_at IS NULL
+            CROSS JOIN q
+            WHERE ` + tsv + ` @@ q.query
+        ),
+        ranked AS (
+            SELECT song_id, SUM(rank) AS rank
+            FROM hits
+            GROUP BY song_id
+            ORDER BY rank DESC, song_id
+            LIMIT $2 OFFSET $3
+        )
+        SELECT r.song_id, g.name, s.name, r.rank, h.verse_number, h.line_number,
+               ts_headline(` + cfg + `, h.lyrics_line, q.query, 'StartSel=` + highlightStart + `, StopSel=` + highlightStop + `, HighlightAll=true')
+        FROM ranked r
+        JOIN songs s ON s.id = r.song_id
+        JOIN groups g ON g.id = s.group_id
+        JOIN hits h ON h.song_id = r.song_id
+        CROSS JOIN q
+        ORDER BY r.rank DESC, r.song_id, h.verse_number, h.line_number;
+    `
+	rows, err := s.db.QueryContext(ctx, query, strings.TrimSpace(phrase), limit, offset)
+	if err != nil {
+		s.log(ctx).Printf("Ошибка при поиске по тексту песен: %v", err)
+		return nil, translateError(err)
+	}
+	defer rows.Close()
+
+	results := []storages.SearchResult{}
+	for rows.Next() {
+		var result storages.SearchResult
+		var match storages.SearchMatch
+		if err := rows.Scan(&result.SongID, &result.Group, &result.Song, &result.Rank,
+			&match.Verse, &match.Line, &match.Text); err != nil {
+			s.log(ctx).Printf("Ошибка при сканировании результата поиска: %v", err)
+			return nil, translateError(err)
+		}
+		match.Text = highlight(match.Text)
+		// Строки одной песни идут подряд, поэтому группируем их по смене song_id
+		if n := len(results); n == 0 || results[n-1].SongID != result.SongID {
+			results = append(results, result)
+		}
+		last := &results[len(results)-1]
+		last.Matches = append(last.Matches, match)
+	}
+	return results, rows.Err()
+}
+
+// highlight экранирует строку текста для HTML и заменяет границы совпадений на <mark></mark>.
+// Текст песен вводят пользователи, поэтому без экранирования выделение стало бы хранимой XSS.
+// Непарные границы, если они всё же встретились в тексте, не нарушают разметку
+func highlight(line string) string {
+	var b strings.Builder
+	open := false
+	for {
+		i := strings.IndexAny(line, highlightStart+highlightStop)
+		if i < 0 {
+			break
+		}
+		b.WriteString(html.EscapeString(line[:i]))
+		_, size := utf8.DecodeRuneInString(line[i:])
+		marker := line[i : i+size]
+		line = line[i+size:]
+		switch {
+		case marker == highlightStart && !open:
+			b.WriteString("<mark>")
+			open = true
+		case marker == highlightStop && open:
+			b.WriteString("</mark>")
+			open = false
+		}
+	}
+	b.WriteString(html.EscapeString(line))
+	if open {
+		b.WriteString("</mark>")
+	}
+	return b.String()
+}
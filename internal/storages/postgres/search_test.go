@@ -0,0 +1,26 @@
+package postgres
+
+import "testing"
+
+func TestHighlight(t *testing.T) {
+	const start, stop = highlightStart, highlightStop
+	tests := []struct {
+		name string
+		line string
+		want string
+	}{
+		{name: "без совпадений", line: "Ooh baby", want: "Ooh baby"},
+		{name: "совпадение", line: "Ooh " + start + "baby" + stop + ", don't", want: "Ooh <mark>baby</mark>, don&#39;t"},
+		{name: "разметка в тексте экранируется", line: "<script>alert(1)</script> " + start + "baby" + stop, want: "&lt;script&gt;alert(1)&lt;/script&gt; <mark>baby</mark>"},
+		{name: "разметка внутри совпадения", line: start + "<b>baby</b>" + stop, want: "<mark>&lt;b&gt;baby&lt;/b&gt;</mark>"},
+		{name: "незакрытое совпадение", line: start + "baby", want: "<mark>baby</mark>"},
+		{name: "лишние границы", line: stop + "baby" + start + start + "you" + stop + stop, want: "baby<mark>you</mark>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := highlight(tt.line); got != tt.want {
+				t.Fatalf("highlight(%q) = %q, ожидается %q", tt.line, got, tt.want)
+			}
+		})
+	}
+}
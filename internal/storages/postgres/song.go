@@ -1,150 +1,604 @@
 package postgres
 
 import (
-	"fmt"
-	_ "github.com/lib/pq"
-	"github.com/sirupsen/logrus"
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/lib/pq"
 	"songs/internal/storages"
+	"strconv"
+	"strings"
 )
 
-func (s *PostgresStorage) AddLyrics(songID int, line string) error {
-	query := `INSERT INTO song_lyrics (song_id, lyrics_line) VALUES ($1, $2)`
-	_, err := s.db.Exec(query, songID, line)
+// AddLyrics добавляет новый куплет в конец текста песни и возвращает его с присвоенным номером
+func (s *PostgresStorage) AddLyrics(ctx context.Context, actor string, songID int, lines []string) (storages.Verse, error) {
+	ctx, cancel := s.withTimeout(ctx, s.timeouts.Write)
+	defer cancel()
+
+	verse := storages.Verse{Lines: lines}
+
+	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
-		s.logger.Printf("Ошибка при добавлении строки текста песни (songID: %d): %v", songID, err)
-		return err
+		s.log(ctx).Printf("Ошибка при открытии транзакции: %v", err)
+		return verse, translateError(err)
+	}
+	defer tx.Rollback()
+
+	// Блокируем песню, чтобы параллельные вставки не получили одинаковый номер куплета
+	var exists bool
+	err = tx.QueryRowContext(ctx, `SELECT true FROM songs WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`, songID).Scan(&exists)
+	if errors.Is(err, sql.ErrNoRows) {
+		return verse, storages.ErrNotFound
+	}
+	if err != nil {
+		s.log(ctx).Printf("Ошибка при получении песни (ID: %d): %v", songID, err)
+		return verse, translateError(err)
+	}
+	before, err := songSnapshot(ctx, tx, songID)
+	if err != nil {
+		s.log(ctx).Printf("Ошибка при получении песни (ID: %d): %v", songID, err)
+		return verse, translateError(err)
+	}
+
+	query := `SELECT COALESCE(MAX(verse_number), 0) + 1 FROM song_lyrics WHERE song_id = $1`
+	if err := tx.QueryRowContext(ctx, query, songID).Scan(&verse.Number); err != nil {
+		s.log(ctx).Printf("Ошибка при определении номера куплета (songID: %d): %v", songID, err)
+		return verse, translateError(err)
+	}
+
+	if err := insertVerse(ctx, tx, songID, verse); err != nil {
+		s.log(ctx).Printf("Ошибка при добавлении куплета песни (songID: %d): %v", songID, err)
+		return verse, translateError(err)
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE songs SET version = version + 1, updated_at = now() WHERE id = $1`, songID); err != nil {
+		s.log(ctx).Printf("Ошибка при обновлении версии песни (ID: %d): %v", songID, err)
+		return verse, translateError(err)
+	}
+	if err := recordChange(ctx, tx, songID, storages.AuditLyricsAdd, actor, before); err != nil {
+		s.log(ctx).Printf("Ошибка при записи в журнал изменений (songID: %d): %v", songID, err)
+		return verse, translateError(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		s.log(ctx).Printf("Ошибка при фиксации транзакции: %v", err)
+		return verse, translateError(err)
+	}
+	return verse, nil
+}
+
+// insertVerse сохраняет строки куплета с их порядковыми номерами в рамках транзакции
+func insertVerse(ctx context.Context, tx *sql.Tx, songID int, verse storages.Verse) error {
+	query := `INSERT INTO song_lyrics (song_id, verse_number, line_number, lyrics_line) VALUES ($1, $2, $3, $4)`
+	for i, line := range verse.Lines {
+		if _, err := tx.ExecContext(ctx, query, songID, verse.Number, i+1, line); err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
-func (s *PostgresStorage) UpdateSongPartial(id int, updates map[string]interface{}) error {
-	query := `UPDATE songs SET `
-	params := []interface{}{}
-	i := 1
+// UpdateSongPartial применяет патч к песне и возвращает обновлённую песню.
+// Столбцы для обновления выбираются только из известных полей патча.
+// Смена группы создаёт её при отсутствии, как и при добавлении песни
+func (s *PostgresStorage) UpdateSongPartial(ctx context.Context, actor string, id int, patch storages.SongPatch, version int) (storages.Song, error) {
+	return s.updateSong(ctx, actor, storages.AuditPatch, id, patch, version)
+}
 
-	for key, value := range updates {
-		if i > 1 {
-			query += ", "
-		}
-		query += fmt.Sprintf(`"%s" = $%d`, key, i)
-		params = append(params, value)
-		i++
+// updateSong применяет патч и фиксирует изменение в журнале под переданным действием
+func (s *PostgresStorage) updateSong(ctx context.Context, actor string, action string, id int, patch storages.SongPatch, version int) (storages.Song, error) {
+	ctx, cancel := s.withTimeout(ctx, s.timeouts.Write)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		s.log(ctx).Printf("Ошибка при открытии транзакции: %v", err)
+		return storages.Song{}, translateError(err)
 	}
-	query += " WHERE id = $1"
-	params = append(params, id)
+	defer tx.Rollback()
 
-	_, err := s.db.Exec(query, params...)
+	if err := lockSongVersion(ctx, tx, id, version); err != nil {
+		if !errors.Is(err, storages.ErrNotFound) && !errors.Is(err, storages.ErrVersionMismatch) {
+			s.log(ctx).Printf("Ошибка при получении песни (ID: %d): %v", id, err)
+		}
+		return storages.Song{}, translateError(err)
+	}
+	before, err := songSnapshot(ctx, tx, id)
 	if err != nil {
-		s.logger.Printf("Ошибка при частичном обновлении песни (ID: %d): %v", id, err)
-		return err
+		s.log(ctx).Printf("Ошибка при получении песни (ID: %d): %v", id, err)
+		return storages.Song{}, translateError(err)
 	}
-	return nil
+
+	b := &queryBuilder{}
+	var set []string
+	if patch.Group != nil {
+		groupID, err := upsertGroup(ctx, tx, *patch.Group)
+		if err != nil {
+			s.log(ctx).Printf("Ошибка при создании группы (%s): %v", *patch.Group, err)
+			return storages.Song{}, translateError(err)
+		}
+		set = append(set, "group_id = "+b.arg(groupID))
+	}
+	if patch.Name != nil {
+		set = append(set, "name = "+b.arg(*patch.Name))
+	}
+	if patch.ReleaseDate != nil {
+		set = append(set, "release_date = NULLIF("+b.arg(*patch.ReleaseDate)+", '')::date")
+	}
+	if patch.Link != nil {
+		set = append(set, "link = NULLIF("+b.arg(*patch.Link)+", '')")
+	}
+
+	if len(set) > 0 {
+		set = append(set, "version = version + 1", "updated_at = now()")
+		query := `UPDATE songs SET ` + strings.Join(set, ", ") + ` WHERE id = ` + b.arg(id)
+		if err := expectAffected(tx.ExecContext(ctx, query, b.args...)); err != nil {
+			s.log(ctx).Printf("Ошибка при частичном обновлении песни (ID: %d): %v", id, err)
+			return storages.Song{}, translateError(err)
+		}
+		if err := recordChange(ctx, tx, id, action, actor, before); err != nil {
+			s.log(ctx).Printf("Ошибка при записи в журнал изменений (songID: %d): %v", id, err)
+			return storages.Song{}, translateError(err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		s.log(ctx).Printf("Ошибка при фиксации транзакции: %v", err)
+		return storages.Song{}, translateError(err)
+	}
+	s.log(ctx).Printf("Успешно обновлена песня (ID: %d)", id)
+	return s.GetSong(ctx, id)
 }
 
-func (s *PostgresStorage) GetSongs(group string, song string, page int, limit int) ([]storages.Song, error) {
-	offset := (page - 1) * limit
+// GetSongs возвращает страницу песен, отобранных и упорядоченных по фильтру.
+// Страница выбирается по курсору (keyset), если он задан, иначе через OFFSET
+func (s *PostgresStorage) GetSongs(ctx context.Context, filter storages.SongFilter, p storages.Pagination) (storages.SongsPage, error) {
+	ctx, cancel := s.withTimeout(ctx, s.timeouts.Read)
+	defer cancel()
+
+	page := storages.SongsPage{Items: []storages.Song{}}
+	page.Limit = p.Limit
+
+	keys, err := songSortKeys(filter.Sort)
+	if err != nil {
+		return page, translateError(err)
+	}
+	signature := sortSignature(filter.Sort)
+
+	b := songFilterQuery(filter)
+	from := `
+        FROM songs s
+        JOIN groups g ON s.group_id = g.id
+        ` + b.whereClause()
+
+	if err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) `+from, b.args...).Scan(&page.Total); err != nil {
+		s.log(ctx).Printf("Ошибка при подсчёте песен: %v", err)
+		return page, translateError(err)
+	}
+
+	var cursor *storages.Cursor
+	pagination := ""
+	if p.Cursor != "" {
+		c, err := storages.DecodeCursor(p.Cursor)
+		if err != nil || c.Sort != signature || len(c.Keys) != len(keys) {
+			return page, storages.ErrInvalidCursor
+		}
+		cursor = &c
+		b.keyset(keys, c.Keys, c.Backward)
+	} else {
+		page.Page = p.Page
+		pagination = " OFFSET " + b.arg((p.Page-1)*p.Limit)
+	}
+	backward := cursor != nil && cursor.Backward
+
+	// Запрашиваем на одну строку больше, чтобы узнать, есть ли следующая страница
 	query := `
-        SELECT s.id, g.name AS group_name, s.name, s.release_date, s.link
+        SELECT s.id, s.group_id, g.name AS group_name, s.name, COALESCE(s.release_date::text, ''), COALESCE(s.link, ''), s.enrichment_status, s.version, s.updated_at, s.deleted_at,
+               ` + keyColumns(keys) + `
         FROM songs s
         JOIN groups g ON s.group_id = g.id
-        WHERE g.name ILIKE $1 AND s.name ILIKE $2
-        LIMIT $3 OFFSET $4;
+        ` + b.whereClause() + `
+        ` + orderBy(keys, backward) + `
+        LIMIT ` + b.arg(p.Limit+1) + pagination + `;
     `
-	rows, err := s.db.Query(query, "%"+group+"%", "%"+song+"%", limit, offset)
+	rows, err := s.db.QueryContext(ctx, query, b.args...)
 	if err != nil {
-		s.logger.Printf("Ошибка при получении песен: %v", err)
-		return nil, err
+		s.log(ctx).Printf("Ошибка при получении песен: %v", err)
+		return page, translateError(err)
 	}
 	defer rows.Close()
 
-	var songs []storages.Song
+	var cursorKeys [][]string
 	for rows.Next() {
 		var song storages.Song
-		if err := rows.Scan(&song.ID, &song.Group, &song.Name, &song.ReleaseDate, &song.Link); err != nil {
-			s.logger.Printf("Ошибка при сканировании результата: %v", err)
-			return nil, err
+		values := make([]string, len(keys))
+		dest := []interface{}{&song.ID, &song.GroupID, &song.Group, &song.Name, &song.ReleaseDate, &song.Link, &song.EnrichmentStatus, &song.Version, &song.UpdatedAt, &song.DeletedAt}
+		for i := range values {
+			dest = append(dest, &values[i])
+		}
+		if err := rows.Scan(dest...); err != nil {
+			s.log(ctx).Printf("Ошибка при сканировании результата: %v", err)
+			return page, translateError(err)
 		}
-		songs = append(songs, song)
+		page.Items = append(page.Items, song)
+		cursorKeys = append(cursorKeys, values)
+	}
+	if err := rows.Err(); err != nil {
+		return page, translateError(err)
+	}
+
+	extra := len(page.Items) > p.Limit
+	if extra {
+		page.Items = page.Items[:p.Limit]
+		cursorKeys = cursorKeys[:p.Limit]
+	}
+	if backward {
+		reverse(page.Items)
+		reverse(cursorKeys)
 	}
-	return songs, nil
+	pageLinks(&page.PageInfo, cursorKeys, extra, cursor, signature)
+	return page, nil
 }
 
-func (s *PostgresStorage) GetLyrics(songID int, page int, limit int) ([]string, error) {
-	offset := (page - 1) * limit
+// GetLyrics возвращает страницу текста песни, где единицей пагинации является куплет.
+// Страница выбирается по курсору (keyset по номеру куплета), если он задан, иначе через OFFSET
+func (s *PostgresStorage) GetLyrics(ctx context.Context, songID int, p storages.Pagination) (storages.VersesPage, error) {
+	ctx, cancel := s.withTimeout(ctx, s.timeouts.Read)
+	defer cancel()
+
+	page := storages.VersesPage{Items: []storages.Verse{}}
+	page.Limit = p.Limit
+	const signature = "verse"
+
+	// Текст песен из корзины не выдаётся
+	const activeSong = `song_id IN (SELECT id FROM songs WHERE deleted_at IS NULL)`
+
+	// Песня без текста отдаёт пустую страницу, отсутствующая или удалённая в корзину — ErrNotFound
+	var exists bool
+	countQuery := `
+        SELECT EXISTS (SELECT 1 FROM songs WHERE id = $1 AND deleted_at IS NULL),
+               (SELECT COUNT(DISTINCT verse_number) FROM song_lyrics WHERE song_id = $1);
+    `
+	if err := s.db.QueryRowContext(ctx, countQuery, songID).Scan(&exists, &page.Total); err != nil {
+		s.log(ctx).Printf("Ошибка при подсчёте куплетов песни: %v", err)
+		return page, translateError(err)
+	}
+	if !exists {
+		return page, storages.ErrNotFound
+	}
+
+	b := &queryBuilder{}
+	b.where("song_id = " + b.arg(songID))
+	b.where(activeSong)
+
+	keys := []sortKey{{sortColumn: sortColumn{expr: "verse_number", cast: "int"}}}
+	var cursor *storages.Cursor
+	pagination := ""
+	if p.Cursor != "" {
+		c, err := storages.DecodeCursor(p.Cursor)
+		if err != nil || c.Sort != signature || len(c.Keys) != len(keys) {
+			return page, storages.ErrInvalidCursor
+		}
+		cursor = &c
+		b.keyset(keys, c.Keys, c.Backward)
+	} else {
+		page.Page = p.Page
+		pagination = " OFFSET " + b.arg((p.Page-1)*p.Limit)
+	}
+	backward := cursor != nil && cursor.Backward
+
+	versesQuery := `
+        SELECT DISTINCT verse_number
+        FROM song_lyrics
+        ` + b.whereClause() + `
+        ` + orderBy(keys, backward) + `
+        LIMIT ` + b.arg(p.Limit+1) + pagination + `;
+    `
+	rows, err := s.db.QueryContext(ctx, versesQuery, b.args...)
+	if err != nil {
+		s.log(ctx).Printf("Ошибка при получении куплетов песни: %v", err)
+		return page, translateError(err)
+	}
+	var numbers []int64
+	for rows.Next() {
+		var number int64
+		if err := rows.Scan(&number); err != nil {
+			rows.Close()
+			s.log(ctx).Printf("Ошибка при сканировании номера куплета: %v", err)
+			return page, translateError(err)
+		}
+		numbers = append(numbers, number)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return page, translateError(err)
+	}
+
+	extra := len(numbers) > p.Limit
+	if extra {
+		numbers = numbers[:p.Limit]
+	}
+
 	query := `
-        SELECT lyrics_line
+        SELECT verse_number, lyrics_line
         FROM song_lyrics
-        WHERE song_id = $1
-        LIMIT $2 OFFSET $3;
+        WHERE song_id = $1 AND verse_number = ANY($2)
+        ORDER BY verse_number, line_number;
     `
-	rows, err := s.db.Query(query, songID, limit, offset)
+	rows, err = s.db.QueryContext(ctx, query, songID, pq.Array(numbers))
 	if err != nil {
-		s.logger.Printf("Ошибка при получении текста песни: %v", err)
-		return nil, err
+		s.log(ctx).Printf("Ошибка при получении текста песни: %v", err)
+		return page, translateError(err)
 	}
 	defer rows.Close()
 
-	var lyrics []string
+	var cursorKeys [][]string
 	for rows.Next() {
+		var number int
 		var line string
-		if err := rows.Scan(&line); err != nil {
-			s.logger.Printf("Ошибка при сканировании текста: %v", err)
-			return nil, err
+		if err := rows.Scan(&number, &line); err != nil {
+			s.log(ctx).Printf("Ошибка при сканировании текста: %v", err)
+			return page, translateError(err)
 		}
-		lyrics = append(lyrics, line)
+		if n := len(page.Items); n == 0 || page.Items[n-1].Number != number {
+			page.Items = append(page.Items, storages.Verse{Number: number})
+			cursorKeys = append(cursorKeys, []string{strconv.Itoa(number)})
+		}
+		last := &page.Items[len(page.Items)-1]
+		last.Lines = append(last.Lines, line)
+	}
+	if err := rows.Err(); err != nil {
+		return page, translateError(err)
 	}
-	return lyrics, nil
+
+	pageLinks(&page.PageInfo, cursorKeys, extra, cursor, signature)
+	return page, nil
 }
 
-func (s *PostgresStorage) DeleteSong(id int) error {
-	query := `DELETE FROM songs WHERE id = $1`
-	_, err := s.db.Exec(query, id)
+func (s *PostgresStorage) DeleteSong(ctx context.Context, actor string, id int, version int) error {
+	ctx, cancel := s.withTimeout(ctx, s.timeouts.Write)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
-		s.logger.Printf("Ошибка при удалении песни (ID: %d): %v", id, err)
-		return err
+		s.log(ctx).Printf("Ошибка при открытии транзакции: %v", err)
+		return translateError(err)
 	}
-	s.logger.Printf("Успешно удалена песня (ID: %d)", id)
+	defer tx.Rollback()
+
+	if err := lockSongVersion(ctx, tx, id, version); err != nil {
+		if !errors.Is(err, storages.ErrNotFound) && !errors.Is(err, storages.ErrVersionMismatch) {
+			s.log(ctx).Printf("Ошибка при получении песни (ID: %d): %v", id, err)
+		}
+		return translateError(err)
+	}
+	before, err := songSnapshot(ctx, tx, id)
+	if err != nil {
+		s.log(ctx).Printf("Ошибка при получении песни (ID: %d): %v", id, err)
+		return translateError(err)
+	}
+
+	query := `UPDATE songs SET deleted_at = now(), version = version + 1, updated_at = now() WHERE id = $1`
+	if err := expectAffected(tx.ExecContext(ctx, query, id)); err != nil {
+		s.log(ctx).Printf("Ошибка при удалении песни (ID: %d): %v", id, err)
+		return translateError(err)
+	}
+	if err := recordChange(ctx, tx, id, storages.AuditDelete, actor, before); err != nil {
+		s.log(ctx).Printf("Ошибка при записи в журнал изменений (songID: %d): %v", id, err)
+		return translateError(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		s.log(ctx).Printf("Ошибка при фиксации транзакции: %v", err)
+		return translateError(err)
+	}
+	s.log(ctx).Printf("Песня перемещена в корзину (ID: %d)", id)
 	return nil
 }
 
-func (s *PostgresStorage) UpdateSong(id int, song storages.Song) error {
-	query := `UPDATE songs SET "group" = $1, song = $2 WHERE id = $3`
-	_, err := s.db.Exec(query, song.Group, song.Name, id)
+// lockTrashedSong блокирует строку песни из корзины до конца транзакции и возвращает её состояние
+func lockTrashedSong(ctx context.Context, tx *sql.Tx, id int) (*storages.SongSnapshot, error) {
+	var exists bool
+	err := tx.QueryRowContext(ctx, `SELECT true FROM songs WHERE id = $1 AND deleted_at IS NOT NULL FOR UPDATE`, id).Scan(&exists)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, storages.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return songSnapshot(ctx, tx, id)
+}
+
+// RestoreSong возвращает песню из корзины
+func (s *PostgresStorage) RestoreSong(ctx context.Context, actor string, id int) (storages.Song, error) {
+	ctx, cancel := s.withTimeout(ctx, s.timeouts.Write)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		s.log(ctx).Printf("Ошибка при открытии транзакции: %v", err)
+		return storages.Song{}, translateError(err)
+	}
+	defer tx.Rollback()
+
+	before, err := lockTrashedSong(ctx, tx, id)
+	if err != nil {
+		if !errors.Is(err, storages.ErrNotFound) {
+			s.log(ctx).Printf("Ошибка при получении песни (ID: %d): %v", id, err)
+		}
+		return storages.Song{}, translateError(err)
+	}
+
+	query := `UPDATE songs SET deleted_at = NULL, version = version + 1, updated_at = now() WHERE id = $1`
+	if err := expectAffected(tx.ExecContext(ctx, query, id)); err != nil {
+		s.log(ctx).Printf("Ошибка при восстановлении песни (ID: %d): %v", id, err)
+		return storages.Song{}, translateError(err)
+	}
+	if err := recordChange(ctx, tx, id, storages.AuditRestore, actor, before); err != nil {
+		s.log(ctx).Printf("Ошибка при записи в журнал изменений (songID: %d): %v", id, err)
+		return storages.Song{}, translateError(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		s.log(ctx).Printf("Ошибка при фиксации транзакции: %v", err)
+		return storages.Song{}, translateError(err)
+	}
+	s.log(ctx).Printf("Песня восстановлена из корзины (ID: %d)", id)
+	return s.GetSong(ctx, id)
+}
+
+// PurgeSong окончательно удаляет песню из корзины. Текст песни удаляется в той же транзакции,
+// а журнал изменений сохраняется
+func (s *PostgresStorage) PurgeSong(ctx context.Context, actor string, id int) error {
+	ctx, cancel := s.withTimeout(ctx, s.timeouts.Write)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		s.log(ctx).Printf("Ошибка при открытии транзакции: %v", err)
+		return translateError(err)
+	}
+	defer tx.Rollback()
+
+	before, err := lockTrashedSong(ctx, tx, id)
+	if err != nil {
+		if !errors.Is(err, storages.ErrNotFound) {
+			s.log(ctx).Printf("Ошибка при получении песни (ID: %d): %v", id, err)
+		}
+		return translateError(err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM song_lyrics WHERE song_id = $1`, id); err != nil {
+		s.log(ctx).Printf("Ошибка при удалении текста песни (ID: %d): %v", id, err)
+		return translateError(err)
+	}
+	if err := expectAffected(tx.ExecContext(ctx, `DELETE FROM songs WHERE id = $1`, id)); err != nil {
+		s.log(ctx).Printf("Ошибка при удалении песни (ID: %d): %v", id, err)
+		return translateDeleteError(err)
+	}
+	if err := recordChange(ctx, tx, id, storages.AuditPurge, actor, before); err != nil {
+		s.log(ctx).Printf("Ошибка при записи в журнал изменений (songID: %d): %v", id, err)
+		return translateError(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		s.log(ctx).Printf("Ошибка при фиксации транзакции: %v", err)
+		return translateError(err)
+	}
+	s.log(ctx).Printf("Песня окончательно удалена (ID: %d)", id)
+	return nil
+}
+
+// UpdateSong полностью заменяет группу, название, дату выхода и ссылку песни
+func (s *PostgresStorage) UpdateSong(ctx context.Context, actor string, id int, song storages.Song, version int) (storages.Song, error) {
+	return s.updateSong(ctx, actor, storages.AuditUpdate, id, storages.SongPatch{
+		Group:       &song.Group,
+		Name:        &song.Name,
+		ReleaseDate: &song.ReleaseDate,
+		Link:        &song.Link,
+	}, version)
+}
+
+// lockSongVersion блокирует строку активной песни до конца транзакции и сверяет её версию с ожидаемой.
+// Версия 0 означает, что проверка не нужна. Песни в корзине считаются отсутствующими
+func lockSongVersion(ctx context.Context, tx *sql.Tx, id int, version int) error {
+	var current int
+	err := tx.QueryRowContext(ctx, `SELECT version FROM songs WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`, id).Scan(&current)
+	if errors.Is(err, sql.ErrNoRows) {
+		return storages.ErrNotFound
+	}
 	if err != nil {
-		s.logger.Printf("Ошибка при обновлении песни (ID: %d): %v", id, err)
 		return err
 	}
-	s.logger.Printf("Успешно обновлена песня (ID: %d)", id)
+	if version != 0 && version != current {
+		return storages.ErrVersionMismatch
+	}
 	return nil
 }
 
-func (s *PostgresStorage) AddSong(song storages.Song) error {
+func (s *PostgresStorage) GetSong(ctx context.Context, id int) (storages.Song, error) {
+	ctx, cancel := s.withTimeout(ctx, s.timeouts.Read)
+	defer cancel()
+
+	var song storages.Song
 	query := `
-        INSERT INTO songs (group_id, name, release_date, link)
-        VALUES ((SELECT id FROM groups WHERE name = $1), $2, $3, $4);
+        SELECT s.id, s.group_id, g.name, s.name, COALESCE(s.release_date::text, ''), COALESCE(s.link, ''), s.enrichment_status, s.version, s.updated_at, s.deleted_at
+        FROM songs s
+        JOIN groups g ON s.group_id = g.id
+        WHERE s.id = $1 AND s.deleted_at IS NULL;
     `
-	_, err := s.db.Exec(query, song.Group, song.Name, song.ReleaseDate, song.Link)
+	err := s.db.QueryRowContext(ctx, query, id).Scan(&song.ID, &song.GroupID, &song.Group, &song.Name, &song.ReleaseDate, &song.Link, &song.EnrichmentStatus, &song.Version, &song.UpdatedAt, &song.DeletedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return song, storages.ErrNotFound
+	}
 	if err != nil {
-		s.logger.Printf("Ошибка при добавлении песни (группа: %s, песня: %s): %v", song.Group, song.Name, err)
-		return err
+		s.log(ctx).Printf("Ошибка при получении песни (ID: %d): %v", id, err)
+		return song, translateError(err)
 	}
-	s.logger.Printf("Песня успешно добавлена (группа: %s, песня: %s)", song.Group, song.Name)
-	return nil
+	return song, nil
 }
 
-func (s *PostgresStorage) CreateIndexes() error {
-	if s.logger == nil {
-		s.logger = logrus.New() // Если логгер не был инициализирован, создаем новый
+// AddSong добавляет песню вместе с текстом в одной транзакции и возвращает ID новой песни.
+// Куплеты нумеруются заново в переданном порядке. Песня со статусом обогащения pending
+// в той же транзакции ставится в очередь фонового обогащения.
+func (s *PostgresStorage) AddSong(ctx context.Context, actor string, song storages.Song, verses []storages.Verse) (int, error) {
+	ctx, cancel := s.withTimeout(ctx, s.timeouts.Write)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		s.log(ctx).Printf("Ошибка при открытии транзакции: %v", err)
+		return 0, translateError(err)
 	}
-	_, err := s.db.Exec(`
-		CREATE INDEX IF NOT EXISTS idx_group_name ON songs(group_id);
-		CREATE INDEX IF NOT EXISTS idx_song_name ON songs(name);
-		CREATE INDEX IF NOT EXISTS idx_song_id ON song_lyrics(song_id);
-	`)
+	defer tx.Rollback()
+
+	// Группа создаётся автоматически, если её ещё нет в базе
+	groupID, err := upsertGroup(ctx, tx, song.Group)
 	if err != nil {
-		s.logger.Printf("Ошибка при создании индексов: %v", err)
-		return err
+		s.log(ctx).Printf("Ошибка при создании группы (%s): %v", song.Group, err)
+		return 0, translateError(err)
 	}
-	s.logger.Println("Индексы успешно созданы.")
-	return nil
+
+	status := song.EnrichmentStatus
+	if status == "" {
+		status = storages.EnrichmentDone
+	}
+
+	var id int
+	query := `
+        INSERT INTO songs (group_id, name, release_date, link, enrichment_status)
+        VALUES ($1, $2, NULLIF($3, '')::date, NULLIF($4, ''), $5)
+        RETURNING id;
+    `
+	err = tx.QueryRowContext(ctx, query, groupID, song.Name, song.ReleaseDate, song.Link, status).Scan(&id)
+	if err != nil {
+		s.log(ctx).Printf("Ошибка при добавлении песни (группа: %s, песня: %s): %v", song.Group, song.Name, err)
+		return 0, translateError(err)
+	}
+
+	for i, verse := range verses {
+		verse.Number = i + 1
+		if err := insertVerse(ctx, tx, id, verse); err != nil {
+			s.log(ctx).Printf("Ошибка при добавлении куплета песни (songID: %d): %v", id, err)
+			return 0, translateError(err)
+		}
+	}
+
+	if status == storages.EnrichmentPending {
+		if err := enqueueEnrichment(ctx, tx, id); err != nil {
+			s.log(ctx).Printf("Ошибка при постановке песни в очередь обогащения (ID: %d): %v", id, err)
+			return 0, translateError(err)
+		}
+	}
+
+	if err := recordChange(ctx, tx, id, storages.AuditCreate, actor, nil); err != nil {
+		s.log(ctx).Printf("Ошибка при записи в журнал изменений (songID: %d): %v", id, err)
+		return 0, translateError(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		s.log(ctx).Printf("Ошибка при фиксации транзакции: %v", err)
+		return 0, translateError(err)
+	}
+	s.log(ctx).Printf("Песня успешно добавлена (ID: %d, группа: %s, песня: %s)", id, song.Group, song.Name)
+	return id, nil
 }
@@ -1,11 +1,75 @@
 package storages
 
+import (
+	"context"
+	"time"
+)
+
+// Методы изменения песни принимают ожидаемую версию песни. Если она не совпадает с текущей,
+// возвращается ErrVersionMismatch. Версия 0 отключает проверку.
+// Каждое изменение песни вместе с автором actor записывается в журнал в той же транзакции.
+// Все методы принимают контекст запроса: его отмена или истечение срока прерывает запрос к базе
 type Storages interface {
-	GetSongs(group string, song string, page int, limit int) ([]Song, error)
-	GetLyrics(songID int, page int, limit int) ([]string, error)
-	DeleteSong(id int) error
-	UpdateSong(id int, song Song) error
-	AddSong(song Song) error
-	AddLyrics(songID int, line string) error
-	UpdateSongPartial(id int, updates map[string]interface{}) error
+	GetSongs(ctx context.Context, filter SongFilter, p Pagination) (SongsPage, error)
+	GetLyrics(ctx context.Context, songID int, p Pagination) (VersesPage, error)
+	// DeleteSong переносит песню в корзину, RestoreSong возвращает её обратно,
+	// PurgeSong окончательно удаляет песню из корзины вместе с текстом
+	DeleteSong(ctx context.Context, actor string, id int, version int) error
+	RestoreSong(ctx context.Context, actor string, id int) (Song, error)
+	PurgeSong(ctx context.Context, actor string, id int) error
+	UpdateSong(ctx context.Context, actor string, id int, song Song, version int) (Song, error)
+	GetSong(ctx context.Context, id int) (Song, error)
+	AddSong(ctx context.Context, actor string, song Song, verses []Verse) (int, error)
+	AddLyrics(ctx context.Context, actor string, songID int, lines []string) (Verse, error)
+	UpdateSongPartial(ctx context.Context, actor string, id int, patch SongPatch, version int) (Song, error)
+	SearchLyrics(ctx context.Context, phrase string, language string, page int, limit int) ([]SearchResult, error)
+
+	GetEnrichmentJob(ctx context.Context, songID int) (EnrichmentJob, error)
+	RetryEnrichment(ctx context.Context, actor string, songID int) (EnrichmentJob, error)
+
+	// GetSongHistory возвращает журнал изменений песни от новых записей к старым.
+	// RevertSong возвращает песню к состоянию после изменения с номером revision
+	GetSongHistory(ctx context.Context, songID int, page int, limit int) ([]AuditEntry, error)
+	RevertSong(ctx context.Context, actor string, id int, revision int64, version int) (Song, error)
+
+	GetGroups(ctx context.Context, name string, page int, limit int) ([]Group, error)
+	GetGroup(ctx context.Context, id int) (Group, error)
+	AddGroup(ctx context.Context, group Group) (int, error)
+	UpdateGroup(ctx context.Context, actor string, id int, group Group) error
+	DeleteGroup(ctx context.Context, id int) error
+}
+
+// EnrichmentQueue очередь задач фонового обогащения песен, которую разбирают воркеры
+type EnrichmentQueue interface {
+	// ClaimEnrichmentJob захватывает одну готовую к выполнению задачу на время lease.
+	// Возвращает ErrNotFound, если готовых задач нет
+	ClaimEnrichmentJob(ctx context.Context, lease time.Duration) (EnrichmentJob, error)
+	// CompleteEnrichmentJob сохраняет полученные данные песни и закрывает задачу.
+	// Завершение задачи, которая больше не принадлежит воркеру, возвращает ErrJobReleased,
+	// как и перенос и отправка в dead letter
+	CompleteEnrichmentJob(ctx context.Context, job EnrichmentJob, detail SongDetail) error
+	// RescheduleEnrichmentJob возвращает задачу в очередь для повторной попытки в момент runAt
+	RescheduleEnrichmentJob(ctx context.Context, job EnrichmentJob, reason string, runAt time.Time) error
+	// DeadLetterEnrichmentJob окончательно помечает задачу и песню как неудавшиеся
+	DeadLetterEnrichmentJob(ctx context.Context, job EnrichmentJob, reason string) error
+}
+
+// APIKeyStore хранилище ключей доступа машинных клиентов
+type APIKeyStore interface {
+	// CreateAPIKey сохраняет ключ по его хэшу и возвращает ключ с присвоенным ID
+	CreateAPIKey(ctx context.Context, key APIKey, hash string) (APIKey, error)
+	GetAPIKeys(ctx context.Context, page int, limit int) ([]APIKey, error)
+	// RevokeAPIKey отзывает ключ. Повторный отзыв возвращает ErrNotFound
+	RevokeAPIKey(ctx context.Context, id int) error
+	// AuthenticateAPIKey находит действующий ключ по хэшу и отмечает время его использования.
+	// Для неизвестного, отозванного или просроченного ключа возвращает ErrNotFound
+	AuthenticateAPIKey(ctx context.Context, hash string) (APIKey, error)
+}
+
+// Backend хранилище песен вместе с очередью обогащения и ключами API, как его реализует PostgresStorage.
+// Декораторы хранилища (метрики, трассировка) оборачивают его целиком
+type Backend interface {
+	Storages
+	EnrichmentQueue
+	APIKeyStore
 }
@@ -0,0 +1,235 @@
+package tracing
+
+import (
+	"context"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"songs/internal/storages"
+	"time"
+)
+
+// Storage декоратор хранилища, который открывает спан на каждый вызов. Запросы к базе внутри вызова
+// становятся его дочерними спанами с текстом SQL, если подключение открыто через otelsql
+type Storage struct {
+	next   storages.Backend
+	tracer trace.Tracer
+}
+
+var _ storages.Backend = (*Storage)(nil)
+
+// NewStorage оборачивает хранилище next трассировкой
+func NewStorage(next storages.Backend) *Storage {
+	return &Storage{next: next, tracer: otel.Tracer(Name)}
+}
+
+func (s *Storage) start(ctx context.Context, method string) (context.Context, trace.Span) {
+	return s.tracer.Start(ctx, "storage."+method)
+}
+
+// end завершает спан. Отказы по данным запроса отмечаются событием, сбоем считаются только прочие ошибки
+func end(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		if !storages.IsRejection(err) {
+			span.SetStatus(codes.Error, err.Error())
+		}
+	}
+	span.End()
+}
+
+func (s *Storage) GetSongs(ctx context.Context, filter storages.SongFilter, p storages.Pagination) (storages.SongsPage, error) {
+	ctx, span := s.start(ctx, "GetSongs")
+	res, err := s.next.GetSongs(ctx, filter, p)
+	end(span, err)
+	return res, err
+}
+
+func (s *Storage) GetLyrics(ctx context.Context, songID int, p storages.Pagination) (storages.VersesPage, error) {
+	ctx, span := s.start(ctx, "GetLyrics")
+	res, err := s.next.GetLyrics(ctx, songID, p)
+	end(span, err)
+	return res, err
+}
+
+func (s *Storage) DeleteSong(ctx context.Context, actor string, id int, version int) error {
+	ctx, span := s.start(ctx, "DeleteSong")
+	err := s.next.DeleteSong(ctx, actor, id, version)
+	end(span, err)
+	return err
+}
+
+func (s *Storage) RestoreSong(ctx context.Context, actor string, id int) (storages.Song, error) {
+	ctx, span := s.start(ctx, "RestoreSong")
+	res, err := s.next.RestoreSong(ctx, actor, id)
+	end(span, err)
+	return res, err
+}
+
+func (s *Storage) PurgeSong(ctx context.Context, actor string, id int) error {
+	ctx, span := s.start(ctx, "PurgeSong")
+	err := s.next.PurgeSong(ctx, actor, id)
+	end(span, err)
+	return err
+}
+
+func (s *Storage) UpdateSong(ctx context.Context, actor string, id int, song storages.Song, version int) (storages.Song, error) {
+	ctx, span := s.start(ctx, "UpdateSong")
+	res, err := s.next.UpdateSong(ctx, actor, id, song, version)
+	end(span, err)
+	return res, err
+}
+
+func (s *Storage) GetSong(ctx context.Context, id int) (storages.Song, error) {
+	ctx, span := s.start(ctx, "GetSong")
+	res, err := s.next.GetSong(ctx, id)
+	end(span, err)
+	return res, err
+}
+
+func (s *Storage) AddSong(ctx context.Context, actor string, song storages.Song, verses []storages.Verse) (int, error) {
+	ctx, span := s.start(ctx, "AddSong")
+	res, err := s.next.AddSong(ctx, actor, song, verses)
+	end(span, err)
+	return res, err
+}
+
+func (s *Storage) AddLyrics(ctx context.Context, actor string, songID int, lines []string) (storages.Verse, error) {
+	ctx, span := s.start(ctx, "AddLyrics")
+	res, err := s.next.AddLyrics(ctx, actor, songID, lines)
+	end(span, err)
+	return res, err
+}
+
+func (s *Storage) UpdateSongPartial(ctx context.Context, actor string, id int, patch storages.SongPatch, version int) (storages.Song, error) {
+	ctx, span := s.start(ctx, "UpdateSongPartial")
+	res, err := s.next.UpdateSongPartial(ctx, actor, id, patch, version)
+	end(span, err)
+	return res, err
+}
+
+func (s *Storage) SearchLyrics(ctx context.Context, phrase string, language string, page int, limit int) ([]storages.SearchResult, error) {
+	ctx, span := s.start(ctx, "SearchLyrics")
+	res, err := s.next.SearchLyrics(ctx, phrase, language, page, limit)
+	end(span, err)
+	return res, err
+}
+
+func (s *Storage) GetEnrichmentJob(ctx context.Context, songID int) (storages.EnrichmentJob, error) {
+	ctx, span := s.start(ctx, "GetEnrichmentJob")
+	res, err := s.next.GetEnrichmentJob(ctx, songID)
+	end(span, err)
+	return res, err
+}
+
+func (s *Storage) RetryEnrichment(ctx context.Context, actor string, songID int) (storages.EnrichmentJob, error) {
+	ctx, span := s.start(ctx, "RetryEnrichment")
+	res, err := s.next.RetryEnrichment(ctx, actor, songID)
+	end(span, err)
+	return res, err
+}
+
+func (s *Storage) GetSongHistory(ctx context.Context, songID int, page int, limit int) ([]storages.AuditEntry, error) {
+	ctx, span := s.start(ctx, "GetSongHistory")
+	res, err := s.next.GetSongHistory(ctx, songID, page, limit)
+	end(span, err)
+	return res, err
+}
+
+func (s *Storage) RevertSong(ctx context.Context, actor string, id int, revision int64, version int) (storages.Song, error) {
+	ctx, span := s.start(ctx, "RevertSong")
+	res, err := s.next.RevertSong(ctx, actor, id, revision, version)
+	end(span, err)
+	return res, err
+}
+
+func (s *Storage) GetGroups(ctx context.Context, name string, page int, limit int) ([]storages.Group, error) {
+	ctx, span := s.start(ctx, "GetGroups")
+	res, err := s.next.GetGroups(ctx, name, page, limit)
+	end(span, err)
+	return res, err
+}
+
+func (s *Storage) GetGroup(ctx context.Context, id int) (storages.Group, error) {
+	ctx, span := s.start(ctx, "GetGroup")
+	res, err := s.next.GetGroup(ctx, id)
+	end(span, err)
+	return res, err
+}
+
+func (s *Storage) AddGroup(ctx context.Context, group storages.Group) (int, error) {
+	ctx, span := s.start(ctx, "AddGroup")
+	res, err := s.next.AddGroup(ctx, group)
+	end(span, err)
+	return res, err
+}
+
+func (s *Storage) UpdateGroup(ctx context.Context, actor string, id int, group storages.Group) error {
+	ctx, span := s.start(ctx, "UpdateGroup")
+	err := s.next.UpdateGroup(ctx, actor, id, group)
+	end(span, err)
+	return err
+}
+
+func (s *Storage) DeleteGroup(ctx context.Context, id int) error {
+	ctx, span := s.start(ctx, "DeleteGroup")
+	err := s.next.DeleteGroup(ctx, id)
+	end(span, err)
+	return err
+}
+
+func (s *Storage) ClaimEnrichmentJob(ctx context.Context, lease time.Duration) (storages.EnrichmentJob, error) {
+	ctx, span := s.start(ctx, "ClaimEnrichmentJob")
+	res, err := s.next.ClaimEnrichmentJob(ctx, lease)
+	end(span, err)
+	return res, err
+}
+
+func (s *Storage) CompleteEnrichmentJob(ctx context.Context, job storages.EnrichmentJob, detail storages.SongDetail) error {
+	ctx, span := s.start(ctx, "CompleteEnrichmentJob")
+	err := s.next.CompleteEnrichmentJob(ctx, job, detail)
+	end(span, err)
+	return err
+}
+
+func (s *Storage) RescheduleEnrichmentJob(ctx context.Context, job storages.EnrichmentJob, reason string, runAt time.Time) error {
+	ctx, span := s.start(ctx, "RescheduleEnrichmentJob")
+	err := s.next.RescheduleEnrichmentJob(ctx, job, reason, runAt)
+	end(span, err)
+	return err
+}
+
+func (s *Storage) DeadLetterEnrichmentJob(ctx context.Context, job storages.EnrichmentJob, reason string) error {
+	ctx, span := s.start(ctx, "DeadLetterEnrichmentJob")
+	err := s.next.DeadLetterEnrichmentJob(ctx, job, reason)
+	end(span, err)
+	return err
+}
+
+func (s *Storage) CreateAPIKey(ctx context.Context, key storages.APIKey, hash string) (storages.APIKey, error) {
+	ctx, span := s.start(ctx, "CreateAPIKey")
+	res, err := s.next.CreateAPIKey(ctx, key, hash)
+	end(span, err)
+	return res, err
+}
+
+func (s *Storage) GetAPIKeys(ctx context.Context, page int, limit int) ([]storages.APIKey, error) {
+	ctx, span := s.start(ctx, "GetAPIKeys")
+	res, err := s.next.GetAPIKeys(ctx, page, limit)
+	end(span, err)
+	return res, err
+}
+
+func (s *Storage) RevokeAPIKey(ctx context.Context, id int) error {
+	ctx, span := s.start(ctx, "RevokeAPIKey")
+	err := s.next.RevokeAPIKey(ctx, id)
+	end(span, err)
+	return err
+}
+
+func (s *Storage) AuthenticateAPIKey(ctx context.Context, hash string) (storages.APIKey, error) {
+	ctx, span := s.start(ctx, "AuthenticateAPIKey")
+	res, err := s.next.AuthenticateAPIKey(ctx, hash)
+	end(span, err)
+	return res, err
+}
@@ -0,0 +1,93 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"os"
+)
+
+// Name имя инструментирования, под которым приложение создаёт свои спаны
+const Name = "songs"
+
+// Options параметры трассировки
+type Options struct {
+	// Экспортёр спанов: none, stdout, file или otlp
+	Exporter string
+	// Файл, в который экспортёр file дописывает спаны в JSON, по одному на строку
+	File string
+	// Адрес коллектора OTLP/HTTP (host:port) и отключение TLS для экспортёра otlp
+	Endpoint string
+	Insecure bool
+	// Доля трасс, которые начинаются в этом сервисе и записываются, от 0 до 1.
+	// Решение вызывающего сервиса, переданное в traceparent, соблюдается
+	SampleRatio float64
+	// Имя сервиса в ресурсе service.name
+	ServiceName string
+}
+
+// Setup настраивает глобальные TracerProvider и пропагатор W3C Trace Context и Baggage.
+// Возвращает функцию, которая дописывает накопленные спаны и останавливает экспортёр.
+// С экспортёром none спаны не записываются, но контекст трассы по-прежнему передаётся дальше
+func Setup(ctx context.Context, opts Options) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var exporter sdktrace.SpanExporter
+	var file *os.File
+	switch opts.Exporter {
+	case "none", "":
+		return func(context.Context) error { return nil }, nil
+	case "stdout":
+		var err error
+		if exporter, err = stdouttrace.New(stdouttrace.WithPrettyPrint()); err != nil {
+			return nil, err
+		}
+	case "file":
+		var err error
+		if file, err = os.OpenFile(opts.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600); err != nil {
+			return nil, fmt.Errorf("не удалось открыть файл трасс: %w", err)
+		}
+		if exporter, err = stdouttrace.New(stdouttrace.WithWriter(file)); err != nil {
+			file.Close()
+			return nil, err
+		}
+	case "otlp":
+		clientOpts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(opts.Endpoint)}
+		if opts.Insecure {
+			clientOpts = append(clientOpts, otlptracehttp.WithInsecure())
+		}
+		var err error
+		if exporter, err = otlptracehttp.New(ctx, clientOpts...); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("неизвестный экспортёр трасс: %s", opts.Exporter)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL,
+		semconv.ServiceName(opts.ServiceName)))
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(opts.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+
+	return func(ctx context.Context) error {
+		err := provider.Shutdown(ctx)
+		if file != nil {
+			file.Close()
+		}
+		return err
+	}, nil
+}
@@ -0,0 +1,4 @@
+ALTER TABLE song_lyrics DROP CONSTRAINT IF EXISTS song_lyrics_position_key;
+ALTER TABLE song_lyrics
+    DROP COLUMN IF EXISTS line_number,
+    DROP COLUMN IF EXISTS verse_number;
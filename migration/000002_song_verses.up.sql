@@ -0,0 +1,16 @@
+ALTER TABLE song_lyrics
+    ADD COLUMN verse_number INT,
+    ADD COLUMN line_number INT;
+
+-- Существующие строки считаются одним куплетом в порядке добавления
+UPDATE song_lyrics l
+SET verse_number = 1,
+    line_number  = n.rn
+FROM (SELECT id, ROW_NUMBER() OVER (PARTITION BY song_id ORDER BY id) AS rn
+      FROM song_lyrics) n
+WHERE l.id = n.id;
+
+ALTER TABLE song_lyrics
+    ALTER COLUMN verse_number SET NOT NULL,
+    ALTER COLUMN line_number SET NOT NULL,
+    ADD CONSTRAINT song_lyrics_position_key UNIQUE (song_id, verse_number, line_number);
@@ -0,0 +1,18 @@
+-- Уже сохранённые песни получили данные синхронно, поэтому считаются обогащёнными
+ALTER TABLE songs ADD COLUMN enrichment_status VARCHAR(16) NOT NULL DEFAULT 'done';
+ALTER TABLE songs ALTER COLUMN enrichment_status SET DEFAULT 'pending';
+
+CREATE TABLE enrichment_jobs (
+                                 id SERIAL PRIMARY KEY,
+                                 song_id INT NOT NULL UNIQUE,
+                                 status VARCHAR(16) NOT NULL DEFAULT 'pending',
+                                 attempts INT NOT NULL DEFAULT 0,
+                                 next_run_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+                                 locked_until TIMESTAMPTZ,
+                                 last_error TEXT,
+                                 created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+                                 updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+                                 FOREIGN KEY (song_id) REFERENCES songs(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_enrichment_jobs_due ON enrichment_jobs (next_run_at) WHERE status IN ('pending', 'running');
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_song_lyrics_tsv_simple;
+DROP INDEX IF EXISTS idx_song_lyrics_tsv_english;
+DROP INDEX IF EXISTS idx_song_lyrics_tsv_russian;
@@ -0,0 +1,4 @@
+-- Индексы полнотекстового поиска по тексту песен для каждой поддерживаемой конфигурации
+CREATE INDEX IF NOT EXISTS idx_song_lyrics_tsv_russian ON song_lyrics USING GIN (to_tsvector('russian', lyrics_line));
+CREATE INDEX IF NOT EXISTS idx_song_lyrics_tsv_english ON song_lyrics USING GIN (to_tsvector('english', lyrics_line));
+CREATE INDEX IF NOT EXISTS idx_song_lyrics_tsv_simple ON song_lyrics USING GIN (to_tsvector('simple', lyrics_line));
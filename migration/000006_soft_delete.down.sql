@@ -0,0 +1,9 @@
+-- Песни из корзины удаляются окончательно вместе с текстом, иначе они снова станут видимыми
+DELETE FROM songs WHERE deleted_at IS NOT NULL;
+
+ALTER TABLE song_lyrics
+    DROP CONSTRAINT IF EXISTS song_lyrics_song_id_fkey,
+    ADD CONSTRAINT song_lyrics_song_id_fkey FOREIGN KEY (song_id) REFERENCES songs(id);
+
+DROP INDEX IF EXISTS idx_songs_deleted_at;
+ALTER TABLE songs DROP COLUMN IF EXISTS deleted_at;
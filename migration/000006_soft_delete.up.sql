@@ -0,0 +1,8 @@
+ALTER TABLE songs ADD COLUMN deleted_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_songs_deleted_at ON songs (deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- Окончательное удаление песни из корзины удаляет и её текст
+ALTER TABLE song_lyrics
+    DROP CONSTRAINT IF EXISTS song_lyrics_song_id_fkey,
+    ADD CONSTRAINT song_lyrics_song_id_fkey FOREIGN KEY (song_id) REFERENCES songs(id) ON DELETE CASCADE;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS song_audit;
+DROP FUNCTION IF EXISTS song_audit_append_only();
@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS song_audit (
+    id         BIGSERIAL PRIMARY KEY,
+    -- Без внешнего ключа: история сохраняется и после окончательного удаления песни
+    song_id    INT NOT NULL,
+    action     TEXT NOT NULL,
+    actor      TEXT NOT NULL,
+    version    INT NOT NULL,
+    before     JSONB,
+    after      JSONB,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_song_audit_song_id ON song_audit (song_id, id);
+
+-- Журнал только дополняется: изменение и удаление записей запрещены
+CREATE OR REPLACE FUNCTION song_audit_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'журнал изменений песен доступен только для добавления';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER song_audit_append_only
+    BEFORE UPDATE OR DELETE ON song_audit
+    FOR EACH ROW EXECUTE FUNCTION song_audit_append_only();
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_song_id;
+DROP INDEX IF EXISTS idx_song_name;
+DROP INDEX IF EXISTS idx_group_name;
@@ -0,0 +1,4 @@
+-- Индексы раньше создавались при запуске приложения, поэтому в существующих базах они уже есть
+CREATE INDEX IF NOT EXISTS idx_group_name ON songs(group_id);
+CREATE INDEX IF NOT EXISTS idx_song_name ON songs(name);
+CREATE INDEX IF NOT EXISTS idx_song_id ON song_lyrics(song_id);
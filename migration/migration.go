@@ -0,0 +1,10 @@
+// Package migration содержит SQL-миграции схемы базы данных.
+// Файлы встраиваются в бинарный файл, поэтому приложение не зависит от рабочего каталога
+package migration
+
+import "embed"
+
+// FS файлы миграций в формате golang-migrate: <версия>_<имя>.up.sql и <версия>_<имя>.down.sql
+//
+//go:embed *.sql
+var FS embed.FS
@@ -0,0 +1,105 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func newTestCache(capacity int, ttl time.Duration) (*TTL[string, int], *time.Time) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := NewTTL[string, int](capacity, ttl)
+	c.now = func() time.Time { return now }
+	return c, &now
+}
+
+func TestTTLGet(t *testing.T) {
+	c, now := newTestCache(2, time.Minute)
+	c.Set("a", 1)
+
+	if v, ok := c.Get("a"); !ok || v != 1 {
+		t.Fatalf("Get(a) = %d, %v, ожидается 1, true", v, ok)
+	}
+	if _, ok := c.Get("b"); ok {
+		t.Fatal("Get(b): найдена отсутствующая запись")
+	}
+
+	*now = now.Add(time.Minute)
+	if _, ok := c.Get("a"); !ok {
+		t.Fatal("запись устарела раньше времени жизни")
+	}
+	*now = now.Add(time.Nanosecond)
+	if _, ok := c.Get("a"); ok {
+		t.Fatal("возвращена устаревшая запись")
+	}
+	if n := c.Len(); n != 0 {
+		t.Fatalf("устаревшая запись не удалена: Len() = %d", n)
+	}
+}
+
+func TestTTLSetRefreshesExpiry(t *testing.T) {
+	c, now := newTestCache(2, time.Minute)
+	c.Set("a", 1)
+	*now = now.Add(30 * time.Second)
+	c.Set("a", 2)
+	*now = now.Add(45 * time.Second)
+
+	if v, ok := c.Get("a"); !ok || v != 2 {
+		t.Fatalf("Get(a) = %d, %v, ожидается 2, true", v, ok)
+	}
+}
+
+func TestTTLEviction(t *testing.T) {
+	tests := []struct {
+		name    string
+		touch   string
+		evicted string
+		kept    []string
+	}{
+		{name: "вытесняется самая старая запись", evicted: "a", kept: []string{"b", "c"}},
+		{name: "чтение продлевает жизнь записи", touch: "a", evicted: "b", kept: []string{"a", "c"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := newTestCache(2, time.Minute)
+			c.Set("a", 1)
+			c.Set("b", 2)
+			if tt.touch != "" {
+				c.Get(tt.touch)
+			}
+			c.Set("c", 3)
+
+			if n := c.Len(); n != 2 {
+				t.Fatalf("Len() = %d, ожидается 2", n)
+			}
+			if _, ok := c.Get(tt.evicted); ok {
+				t.Fatalf("запись %s не вытеснена", tt.evicted)
+			}
+			for _, k := range tt.kept {
+				if _, ok := c.Get(k); !ok {
+					t.Fatalf("запись %s вытеснена", k)
+				}
+			}
+		})
+	}
+}
+
+func TestTTLDisabled(t *testing.T) {
+	tests := []struct {
+		name     string
+		capacity int
+		ttl      time.Duration
+	}{
+		{name: "нулевая ёмкость", capacity: 0, ttl: time.Minute},
+		{name: "нулевое время жизни", capacity: 10, ttl: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := newTestCache(tt.capacity, tt.ttl)
+			c.Set("a", 1)
+			if _, ok := c.Get("a"); ok {
+				t.Fatal("отключённый кэш сохранил запись")
+			}
+		})
+	}
+}
@@ -1,42 +1,74 @@
 package logger
 
 import (
+	"fmt"
 	"github.com/sirupsen/logrus"
+	"gopkg.in/natefinch/lumberjack.v2"
+	"io"
 	"os"
 )
 
-func InitLogger() *logrus.Logger {
+// Options параметры журнала приложения
+type Options struct {
+	// Уровень: debug, info, warn или error
+	Level string
+	// Формат записей: text или json
+	Format string
+	// Куда писать журнал: stdout, file или both
+	Output string
+	// Путь к файлу журнала для вывода file и both
+	File string
+	// Ротация файла: максимальный размер в мегабайтах, количество и возраст в днях старых файлов,
+	// сжатие старых файлов в gzip
+	MaxSizeMB  int
+	MaxBackups int
+	MaxAgeDays int
+	Compress   bool
+}
+
+// InitLogger создаёт логгер по параметрам opts. Каждая запись, сделанная через WithContext
+// с контекстом запроса, получает поле request_id
+func InitLogger(opts Options) (*logrus.Logger, error) {
 	logger := logrus.New()
 
-	logLevel := "debug"
-	switch logLevel {
-	case "debug":
-		logger.SetLevel(logrus.DebugLevel)
-	case "info":
-		logger.SetLevel(logrus.InfoLevel)
-	case "warn":
-		logger.SetLevel(logrus.WarnLevel)
-	case "error":
-		logger.SetLevel(logrus.ErrorLevel)
-	default:
-		logger.SetLevel(logrus.InfoLevel)
+	level, err := logrus.ParseLevel(opts.Level)
+	if err != nil {
+		return nil, fmt.Errorf("неизвестный уровень журнала: %s", opts.Level)
 	}
+	logger.SetLevel(level)
 
-	logFormat := "text"
-	if logFormat == "json" {
+	switch opts.Format {
+	case "json":
 		logger.SetFormatter(&logrus.JSONFormatter{})
-	} else {
+	case "text":
 		logger.SetFormatter(&logrus.TextFormatter{
 			FullTimestamp: true,
 		})
+	default:
+		return nil, fmt.Errorf("неизвестный формат журнала: %s", opts.Format)
 	}
 
-	file, err := os.OpenFile("app.log", os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
-	if err != nil {
-		logger.Fatal(err)
+	// Файл журнала создаётся с правами 0600 и ротируется по размеру
+	file := func() io.Writer {
+		return &lumberjack.Logger{
+			Filename:   opts.File,
+			MaxSize:    opts.MaxSizeMB,
+			MaxBackups: opts.MaxBackups,
+			MaxAge:     opts.MaxAgeDays,
+			Compress:   opts.Compress,
+		}
+	}
+	switch opts.Output {
+	case "stdout":
+		logger.SetOutput(os.Stdout)
+	case "file":
+		logger.SetOutput(file())
+	case "both":
+		logger.SetOutput(io.MultiWriter(os.Stdout, file()))
+	default:
+		return nil, fmt.Errorf("неизвестный вывод журнала: %s", opts.Output)
 	}
 
-	logger.SetOutput(file)
-
-	return logger
+	logger.AddHook(requestIDHook{})
+	return logger, nil
 }
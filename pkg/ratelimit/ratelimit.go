@@ -0,0 +1,106 @@
+// Package ratelimit ограничивает частоту запросов алгоритмом token bucket.
+// Состояние корзин хранится в Store: по умолчанию в памяти процесса, для нескольких
+// экземпляров сервиса его можно заменить общим хранилищем
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// Limit параметры корзины: Rate токенов в секунду пополняется до Burst
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// Result решение по одному запросу
+type Result struct {
+	Allowed bool
+	// Ёмкость корзины и оставшиеся в ней целые токены
+	Limit     int
+	Remaining int
+	// Время до полного пополнения корзины
+	Reset time.Duration
+	// Через сколько появится токен для следующего запроса, если текущий отклонён
+	RetryAfter time.Duration
+}
+
+// Store хранилище корзин. Take списывает токен из корзины key, если он есть
+type Store interface {
+	Take(key string, limit Limit) (Result, error)
+}
+
+// sweepInterval как часто MemoryStore удаляет полные корзины, они неотличимы от новых
+const sweepInterval = time.Minute
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	limit   Limit
+}
+
+// refill пополняет корзину на момент now
+func (b *bucket) refill(now time.Time) {
+	elapsed := now.Sub(b.updated).Seconds()
+	if elapsed > 0 {
+		b.tokens = math.Min(float64(b.limit.Burst), b.tokens+elapsed*b.limit.Rate)
+		b.updated = now
+	}
+}
+
+// MemoryStore хранилище корзин в памяти процесса
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: make(map[string]*bucket), lastSweep: time.Now(), now: time.Now}
+}
+
+func (s *MemoryStore) Take(key string, limit Limit) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if now.Sub(s.lastSweep) >= sweepInterval {
+		s.sweep(now)
+	}
+
+	b, ok := s.buckets[key]
+	if !ok || b.limit != limit {
+		b = &bucket{tokens: float64(limit.Burst), updated: now, limit: limit}
+		s.buckets[key] = b
+	}
+	b.refill(now)
+
+	result := Result{Limit: limit.Burst}
+	if b.tokens >= 1 {
+		b.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = seconds((1 - b.tokens) / limit.Rate)
+	}
+	result.Remaining = int(b.tokens)
+	result.Reset = seconds((float64(limit.Burst) - b.tokens) / limit.Rate)
+	return result, nil
+}
+
+// sweep удаляет корзины, успевшие пополниться полностью
+func (s *MemoryStore) sweep(now time.Time) {
+	for key, b := range s.buckets {
+		b.refill(now)
+		if b.tokens >= float64(b.limit.Burst) {
+			delete(s.buckets, key)
+		}
+	}
+	s.lastSweep = now
+}
+
+func seconds(value float64) time.Duration {
+	return time.Duration(value * float64(time.Second))
+}
@@ -0,0 +1,125 @@
+package resilience
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// fakeClock время, которое тест переводит вручную
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) now() time.Time { return c.t }
+
+func newTestBreaker(threshold int, cooldown time.Duration) (*CircuitBreaker, *fakeClock) {
+	clock := &fakeClock{t: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	b := NewCircuitBreaker(threshold, cooldown)
+	b.now = clock.now
+	return b, clock
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	const cooldown = time.Minute
+
+	// Шаги сценария: вызов метода выключателя или перевод часов
+	// Пустое state означает, что состояние на шаге не проверяется
+	type step struct {
+		do      string
+		advance time.Duration
+		wantErr error
+		state   string
+	}
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{
+			name: "размыкается после threshold неудач подряд",
+			steps: []step{
+				{do: "allow"}, {do: "failure", state: "closed"},
+				{do: "allow"}, {do: "failure", state: "open"},
+				{do: "allow", wantErr: ErrCircuitOpen, state: "open"},
+			},
+		},
+		{
+			name: "успех сбрасывает счётчик неудач",
+			steps: []step{
+				{do: "allow"}, {do: "failure"},
+				{do: "allow"}, {do: "success"},
+				{do: "allow"}, {do: "failure", state: "closed"},
+			},
+		},
+		{
+			name: "после паузы пропускает одну пробу и замыкается после её успеха",
+			steps: []step{
+				{do: "allow"}, {do: "failure"}, {do: "allow"}, {do: "failure", state: "open"},
+				{advance: cooldown - time.Second},
+				{do: "allow", wantErr: ErrCircuitOpen, state: "open"},
+				{advance: time.Second},
+				{do: "allow", state: "half-open"},
+				{do: "allow", wantErr: ErrCircuitOpen, state: "half-open"},
+				{do: "success", state: "closed"},
+				{do: "allow", state: "closed"},
+			},
+		},
+		{
+			name: "неудачная проба снова размыкает",
+			steps: []step{
+				{do: "allow"}, {do: "failure"}, {do: "allow"}, {do: "failure"},
+				{advance: cooldown},
+				{do: "allow", state: "half-open"},
+				{do: "failure", state: "open"},
+				{do: "allow", wantErr: ErrCircuitOpen},
+				{advance: cooldown},
+				{do: "allow", state: "half-open"},
+			},
+		},
+		{
+			name: "Release пробы разрешает новую пробу без смены состояния",
+			steps: []step{
+				{do: "allow"}, {do: "failure"}, {do: "allow"}, {do: "failure"},
+				{advance: cooldown},
+				{do: "allow", state: "half-open"},
+				{do: "release", state: "half-open"},
+				{do: "allow", state: "half-open"},
+				{do: "allow", wantErr: ErrCircuitOpen},
+			},
+		},
+		{
+			name: "Release не сбрасывает счётчик неудач",
+			steps: []step{
+				{do: "allow"}, {do: "failure"},
+				{do: "allow"}, {do: "release", state: "closed"},
+				{do: "allow"}, {do: "failure", state: "open"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, clock := newTestBreaker(2, cooldown)
+			for i, s := range tt.steps {
+				var err error
+				switch s.do {
+				case "allow":
+					err = b.Allow()
+				case "success":
+					b.Success()
+				case "failure":
+					b.Failure()
+				case "release":
+					b.Release()
+				case "":
+					clock.t = clock.t.Add(s.advance)
+					continue
+				}
+				if !errors.Is(err, s.wantErr) {
+					t.Fatalf("шаг %d (%s): ошибка %v, ожидается %v", i, s.do, err, s.wantErr)
+				}
+				if got := b.State().String(); s.state != "" && got != s.state {
+					t.Fatalf("шаг %d (%s): состояние %s, ожидается %s", i, s.do, got, s.state)
+				}
+			}
+		})
+	}
+}
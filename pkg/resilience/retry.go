@@ -0,0 +1,80 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+)
+
+// Backoff параметры экспоненциальной задержки между повторными попытками
+type Backoff struct {
+	// Максимальное количество попыток, включая первую
+	Attempts int
+	// Задержка перед второй попыткой
+	Initial time.Duration
+	// Верхняя граница задержки
+	Max time.Duration
+}
+
+// Delay возвращает задержку перед попыткой с номером attempt (начиная с 1 для первого повтора).
+// К задержке добавляется случайная составляющая до половины её значения.
+func (b Backoff) Delay(attempt int) time.Duration {
+	d := b.Initial
+	for i := 1; i < attempt && d < b.Max; i++ {
+		d *= 2
+	}
+	if b.Max > 0 && d > b.Max {
+		d = b.Max
+	}
+	if d <= 0 {
+		return 0
+	}
+	return d + time.Duration(rand.Int63n(int64(d)/2+1))
+}
+
+type permanentError struct{ err error }
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent помечает ошибку как не подлежащую повтору
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// Retry выполняет fn до успеха, постоянной ошибки, исчерпания попыток или отмены контекста.
+// Постоянная ошибка возвращается без обёртки Permanent.
+func Retry(ctx context.Context, b Backoff, fn func(attempt int) error) error {
+	attempts := b.Attempts
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if err = fn(attempt); err == nil {
+			return nil
+		}
+
+		var permanent *permanentError
+		if errors.As(err, &permanent) {
+			return permanent.err
+		}
+		if attempt == attempts {
+			break
+		}
+
+		timer := time.NewTimer(b.Delay(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+	return err
+}
@@ -0,0 +1,85 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRetry(t *testing.T) {
+	errTemporary := errors.New("временная ошибка")
+	errFatal := errors.New("постоянная ошибка")
+	backoff := Backoff{Attempts: 3, Initial: time.Millisecond, Max: 2 * time.Millisecond}
+
+	tests := []struct {
+		name         string
+		results      []error
+		wantErr      error
+		wantAttempts int
+	}{
+		{name: "успех с первой попытки", results: []error{nil}, wantAttempts: 1},
+		{name: "успех после временных ошибок", results: []error{errTemporary, errTemporary, nil}, wantAttempts: 3},
+		{name: "попытки исчерпаны", results: []error{errTemporary, errTemporary, errTemporary}, wantErr: errTemporary, wantAttempts: 3},
+		{name: "постоянная ошибка не повторяется", results: []error{Permanent(errFatal)}, wantErr: errFatal, wantAttempts: 1},
+		{name: "постоянная ошибка после временной", results: []error{errTemporary, Permanent(errFatal)}, wantErr: errFatal, wantAttempts: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts := 0
+			err := Retry(context.Background(), backoff, func(attempt int) error {
+				attempts++
+				if attempt != attempts {
+					t.Fatalf("номер попытки %d, ожидается %d", attempt, attempts)
+				}
+				return tt.results[attempt-1]
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ошибка %v, ожидается %v", err, tt.wantErr)
+			}
+			var permanent *permanentError
+			if errors.As(err, &permanent) {
+				t.Fatal("постоянная ошибка возвращена в обёртке Permanent")
+			}
+			if attempts != tt.wantAttempts {
+				t.Fatalf("попыток %d, ожидается %d", attempts, tt.wantAttempts)
+			}
+		})
+	}
+}
+
+func TestRetryStopsOnContextCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	attempts := 0
+	err := Retry(ctx, Backoff{Attempts: 5, Initial: time.Hour}, func(int) error {
+		attempts++
+		cancel()
+		return errors.New("временная ошибка")
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("ошибка %v, ожидается %v", err, context.Canceled)
+	}
+	if attempts != 1 {
+		t.Fatalf("попыток %d, ожидается 1", attempts)
+	}
+}
+
+func TestBackoffDelay(t *testing.T) {
+	b := Backoff{Initial: 10 * time.Millisecond, Max: 40 * time.Millisecond}
+	tests := []struct {
+		attempt int
+		base    time.Duration
+	}{
+		{attempt: 1, base: 10 * time.Millisecond},
+		{attempt: 2, base: 20 * time.Millisecond},
+		{attempt: 3, base: 40 * time.Millisecond},
+		{attempt: 10, base: 40 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		// Случайная составляющая не превышает половины задержки
+		if d := b.Delay(tt.attempt); d < tt.base || d > tt.base+tt.base/2 {
+			t.Errorf("Delay(%d) = %v, ожидается от %v до %v", tt.attempt, d, tt.base, tt.base+tt.base/2)
+		}
+	}
+}